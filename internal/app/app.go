@@ -2,21 +2,27 @@ package app
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/adde/kade/internal/config"
 	"github.com/adde/kade/internal/prompts"
 	"github.com/adde/kade/internal/svc"
+	"github.com/adde/kade/internal/utils"
 	"github.com/adde/kade/internal/version"
 	"github.com/briandowns/spinner"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/erikgeiser/promptkit/confirmation"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/client-go/util/homedir"
@@ -25,6 +31,7 @@ import (
 )
 
 var skipVersionCheck bool
+var profileName string
 
 func ParseFlags() {
 	var checkVersion bool
@@ -35,6 +42,8 @@ func ParseFlags() {
 
 	flag.BoolVar(&skipVersionCheck, "skip-version-check", false, "skip checking for latest version of the app")
 
+	flag.StringVar(&profileName, "profile", "", "config profile to use instead of the one matching the kube context")
+
 	flag.BoolVar(&createConfig, "create-config", false, "create config file")
 	flag.BoolVar(&createConfig, "cc", false, "alias for create config file")
 
@@ -49,6 +58,18 @@ func ParseFlags() {
 		config.CreateConfig()
 		os.Exit(0)
 	}
+
+	switch flag.Arg(0) {
+	case "open":
+		Open(flag.Args()[1:])
+		os.Exit(0)
+	case "config":
+		config.Command(flag.Args()[1:])
+		os.Exit(0)
+	case "up":
+		Up(flag.Args()[1:])
+		os.Exit(0)
+	}
 }
 
 func Create() {
@@ -58,83 +79,232 @@ func Create() {
 
 	PrintHeader()
 
-	clientset, rawConfig := InitKubernetesConnection()
-	appConfig := config.GetConfig()
+	clientset, restConfig, rawConfig := InitKubernetesConnection()
+	appConfig := GetAppConfig(rawConfig)
+
 	appType := GetAppType()
 
-	CreateAppByType(clientset, rawConfig, appConfig, appType)
+	CreateAppByType(clientset, restConfig, rawConfig, appConfig, appType)
+}
+
+func GetAppConfig(rawConfig api.Config) *config.Config {
+	appConfig := config.GetConfig()
+
+	selectedProfile, err := appConfig.ApplyProfile(profileName, rawConfig.CurrentContext)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if selectedProfile != "" {
+		fmt.Printf("Using config profile: %s\n\n", selectedProfile)
+	}
+
+	// Secrets are resolved once the profile is merged, values can come from either
+	if err := appConfig.ResolveSecrets(); err != nil {
+		log.Fatal(err)
+	}
+
+	return appConfig
 }
 
-func CreateAppByType(clientset *kubernetes.Clientset, rawConfig api.Config, appConfig *config.Config, appType string) {
+func CreateAppByType(clientset *kubernetes.Clientset, restConfig *rest.Config, rawConfig api.Config, appConfig *config.Config, appType string) {
 	switch appType {
 	case "WordPress":
 		wp := svc.WordPress{
-			Namespace:             prompts.TextInput("Namespace in Rancher/Kubernetes?", svc.WP_PLACEHOLDER_NAMESPACE, "", true),
-			DeploymentName:        prompts.TextInput("Deployment name?", "", svc.WP_PLACEHOLDER_DEPLOYMENT, true),
-			UploadsVolSize:        prompts.TextInput("WordPress uploads volume size(Gi)?", "", svc.WP_PLACEHOLDER_WP_UPLOADS, true),
-			ContainerImage:        prompts.TextInput("Container image to deploy?", "", "wordpress:6.4.2", true),
-			ContainerRegistryUri:  prompts.TextInput("Container registry URI(leave blank if docker.com)?", "", appConfig.Global.ContainerRegistry.Uri, false),
-			ContainerRegistryUser: prompts.TextInput("Container registry user(leave blank if docker.com)?", "", appConfig.Global.ContainerRegistry.User, false),
-			ContainerRegistryPass: prompts.PassWordInput("Container registry password(leave blank if docker.com)?", "", appConfig.Global.ContainerRegistry.Pass, false),
-			Hostname:              prompts.TextInput("Hostname that the web app should be exposed on?", svc.WP_PLACEHOLDER_HOSTNAME, "", true),
-			IngressTls:            prompts.ConfirmationInput("Do you want to configure TLS for the app?", confirmation.No),
-			DatabaseHost:          prompts.TextInput("Database host?", "", appConfig.Global.Database.Host, true),
-			DatabaseName:          prompts.TextInput("Database name?", svc.WP_PLACEHOLDER_DB_NAME, "", true),
-			DatabaseUser:          prompts.TextInput("Database user?", "", appConfig.Global.Database.User, true),
-			DatabasePass:          prompts.PassWordInput("Database password?", "", appConfig.Global.Database.Pass, true),
-			Clientset:             clientset,
-		}
-
-		confirm := prompts.ConfirmationInput(
-			fmt.Sprintf(
-				"Are you sure you want to continue deploying to cluster: %s?",
-				rawConfig.Contexts[rawConfig.CurrentContext].Cluster),
-			confirmation.No)
-
-		if confirm {
-			sepStyle := getSeparatorStyle()
-			fmt.Println(sepStyle.Render(""))
-			fmt.Print("Deploying resources to cluster...\n\n")
-
-			// Create namespace
-			wp.CreateNamespace(
-				"✔ Namespace %s created\n",
-				"⚠ Namespace already exists, continuing...\n")
-
-			// Create PVC
-			wp.CreatePvc(
-				"✔ PVC %s created\n",
-				"⚠ PVC already exists, continuing...\n")
-
-			wp.CreateDbPasswordSecret(
-				"✔ Database password secret %s created\n",
-				"⚠ Database password secret already exists, continuing...\n",
-			)
-
-			wp.CreateRegistryAuthSecret(
-				"✔ Container registry auth secret %s created\n",
-				"⚠ Container registry auth secret already exists, continuing...\n",
-				"⚠ No container registry credentials provided, skipping...\n",
-			)
-
-			wp.CreateDeployment(
-				"✔ WordPress deployment %s created\n",
-				"⚠ WordPress deployment already exists, continuing...\n",
-			)
-
-			wp.CreateService(
-				"✔ WordPress service %s created\n",
-				"⚠ WordPress service already exists, continuing...\n",
-			)
-
-			wp.CreateIngress(
-				"✔ WordPress ingress %s created\n",
-				"⚠ WordPress ingress already exists, continuing...\n",
-			)
-
-			fmt.Println()
-			PrintPreparingEnvironment(wp)
-			PrintEnvironmentReady(wp.GetDeploymentUrl())
+			Namespace:      prompts.TextInput("Namespace in Rancher/Kubernetes?", svc.WP_PLACEHOLDER_NAMESPACE, "", true),
+			DeploymentName: prompts.TextInput("Deployment name?", "", svc.WP_PLACEHOLDER_DEPLOYMENT, true),
+			UploadsVolSize: prompts.ValidatedTextInput("WordPress uploads volume size(Gi)?", "", svc.WP_PLACEHOLDER_WP_UPLOADS, svc.ValidateVolumeSize),
+			Clientset:      clientset,
+			RestConfig:     restConfig,
+		}
+
+		// Projects and quotas are only assigned when kade creates the namespace
+		if !svc.NamespaceExists(clientset, wp.Namespace) {
+			projects, err := svc.GetRancherProjects(restConfig)
+			if err != nil {
+				fmt.Printf("⚠ %s, the namespace will not be assigned to a project\n", err)
+			}
+
+			if len(projects) > 0 {
+				wp.RancherProject = GetRancherProject(projects)
+			}
+
+			wp.QuotaProfile = GetQuotaProfile(appConfig)
+		}
+
+		if storageClasses := svc.GetStorageClasses(clientset); len(storageClasses) > 0 {
+			wp.StorageClass = strings.TrimSuffix(
+				prompts.SelectInput("Storage class for the uploads volume?",
+					preferOption(storageClasses, appConfig.Global.StorageClass, svc.K8S_DEFAULT_STORAGE_CLASS_SUFFIX)),
+				svc.K8S_DEFAULT_STORAGE_CLASS_SUFFIX)
+		}
+
+		wp.UploadsAccessMode = prompts.SelectInput("Access mode for the uploads volume?", svc.K8S_ACCESS_MODES)
+		wp.ContainerImage = prompts.TextInput("Container image to deploy?", "", "wordpress:6.4.2", true)
+		GetRegistryAuth(&wp, appConfig)
+		VerifyContainerImage(&wp)
+
+		// Only offer Gateway API on clusters with gateways available
+		exposures := []string{svc.EXPOSURE_INGRESS, svc.EXPOSURE_INTERNAL}
+		gateways := svc.GetGateways(restConfig)
+		if len(gateways) > 0 {
+			exposures = []string{svc.EXPOSURE_INGRESS, svc.EXPOSURE_GATEWAY, svc.EXPOSURE_INTERNAL}
+		}
+
+		wp.Exposure = prompts.SelectInput("How should the web app be exposed?", exposures)
+
+		if wp.Exposure == svc.EXPOSURE_GATEWAY {
+			wp.GatewayNamespace, wp.GatewayName, _ = strings.Cut(prompts.SelectInput("Gateway?", gateways), "/")
+		}
+
+		if wp.Exposure == svc.EXPOSURE_INGRESS {
+			if ingressClasses := svc.GetIngressClasses(clientset); len(ingressClasses) > 0 {
+				wp.IngressClass = strings.TrimSuffix(
+					prompts.SelectInput("Ingress class?",
+						preferOption(ingressClasses, appConfig.Global.IngressClass, svc.K8S_DEFAULT_INGRESS_CLASS_SUFFIX)),
+					svc.K8S_DEFAULT_INGRESS_CLASS_SUFFIX)
+			}
+
+			wp.IngressController = svc.GetIngressController(clientset, wp.IngressClass)
+		}
+
+		if wp.Exposure != svc.EXPOSURE_INTERNAL {
+			wp.Hostname = prompts.TextInput("Hostname that the web app should be exposed on?", svc.WP_PLACEHOLDER_HOSTNAME, GetHostnameSuggestion(wp, appConfig), true)
+			wp.Aliases = utils.SplitList(prompts.TextInput(
+				"Additional hostnames(comma separated, leave blank for none)?", svc.WP_PLACEHOLDER_ALIASES, "", false))
+
+			if len(wp.Aliases) > 0 && wp.Exposure == svc.EXPOSURE_INGRESS {
+				wp.RedirectAliases = prompts.ConfirmationInput(
+					"Do you want to permanently redirect the additional hostnames to "+wp.Hostname+"?", confirmation.No)
+			}
+
+			wp.IngressTls = prompts.ConfirmationInput("Do you want to configure TLS for the app?", confirmation.No)
+		}
+
+		if err := wp.ValidateGateway(); err != nil {
+			log.Fatal(err)
+		}
+
+		// TLS for gateways is terminated by the gateway listeners
+		if wp.IngressTls && wp.Exposure == svc.EXPOSURE_INGRESS {
+			wp.TlsSource = prompts.SelectInput("Where should the TLS certificate come from?", svc.TLS_SOURCES)
+
+			switch wp.TlsSource {
+			case svc.TLS_SOURCE_CERT_MANAGER:
+				wp.IssuerKind, wp.IssuerName = svc.K8S_CLUSTER_ISSUER_KIND, svc.K8S_CLUSTER_ISSUER_NAME
+				if issuers := svc.GetIssuers(restConfig, wp.Namespace); len(issuers) > 0 {
+					preferredIssuer := ""
+					if appConfig.Global.Issuer != "" {
+						kind, name := parseIssuer(appConfig.Global.Issuer)
+						preferredIssuer = kind + "/" + name
+					}
+
+					wp.IssuerKind, wp.IssuerName, _ = strings.Cut(
+						prompts.SelectInput("cert-manager issuer?", preferOption(issuers, preferredIssuer, "")), "/")
+				}
+			case svc.TLS_SOURCE_EXISTING_SECRET:
+				wp.TlsSecretName = prompts.TextInput("Name of the existing TLS secret?", wp.Namespace+"-tls", "", true)
+			case svc.TLS_SOURCE_UPLOAD:
+				wp.TlsCertFile = utils.ExpandHome(prompts.ValidatedTextInput("Path to the certificate file?", "tls.crt", "", utils.ValidateFileExists))
+				wp.TlsKeyFile = utils.ExpandHome(prompts.ValidatedTextInput("Path to the private key file?", "tls.key", "", utils.ValidateFileExists))
+				if err := svc.ValidateKeyPair(wp.TlsCertFile, wp.TlsKeyFile); err != nil {
+					log.Fatalf("invalid TLS certificate or key: %s", err)
+				}
+			}
+		}
+
+		if wp.Exposure == svc.EXPOSURE_INGRESS && !wp.SupportsAccessControl() {
+			fmt.Println("⚠ Basic auth and IP restrictions are only supported with nginx and Traefik ingress controllers, skipping...")
+		}
+
+		if wp.SupportsAccessControl() {
+			wp.BasicAuth = prompts.ConfirmationInput("Do you want to protect the environment with basic auth?", confirmation.No)
+			if wp.BasicAuth {
+				wp.BasicAuthUser = prompts.TextInput("Basic auth user?", "", svc.WP_PLACEHOLDER_BASIC_AUTH_USER, true)
+			}
+
+			wp.AllowedSourceRanges = utils.SplitList(prompts.ValidatedTextInput(
+				"Restrict access to IP ranges(CIDR, comma separated, leave blank to allow all)?",
+				svc.WP_PLACEHOLDER_SOURCE_RANGES, "", svc.ValidateSourceRanges))
+		}
+
+		wp.DatabaseHost = prompts.TextInput("Database host?", "", appConfig.Global.Database.Host, true)
+		wp.DatabaseName = prompts.TextInput("Database name?", svc.WP_PLACEHOLDER_DB_NAME, "", true)
+		wp.DatabaseUser = prompts.TextInput("Database user?", "", appConfig.Global.Database.User, true)
+		wp.DatabasePass = prompts.PassWordInput("Database password?", "", appConfig.Global.Database.Pass, true)
+
+		wpConfig := appConfig.Global.WordPress
+		setWordPressDefaults(&wp, wpConfig)
+
+		if prompts.ConfirmationInput("Do you want to customize WordPress and PHP settings?", confirmation.No) {
+			wp.Debug = prompts.ConfirmationInput("Enable WP_DEBUG?", confirmation.No)
+			wp.PhpIni[svc.PHP_INI_MEMORY_LIMIT] = prompts.TextInput(
+				"PHP memory limit?", "",
+				utils.ValueOrDefault(wpConfig.PhpIni[svc.PHP_INI_MEMORY_LIMIT], svc.WP_DEFAULT_PHP_MEMORY_LIMIT), true)
+			uploadMaxSize := prompts.TextInput(
+				"PHP upload max filesize?", "",
+				utils.ValueOrDefault(wpConfig.PhpIni[svc.PHP_INI_UPLOAD_MAX_FILESIZE], svc.WP_DEFAULT_UPLOAD_MAX_SIZE), true)
+			wp.PhpIni[svc.PHP_INI_UPLOAD_MAX_FILESIZE] = uploadMaxSize
+			wp.PhpIni[svc.PHP_INI_POST_MAX_SIZE] = uploadMaxSize
+			wp.Constants = utils.ParseKeyValueList(prompts.TextInput(
+				"Custom wp-config constants(NAME=value, comma separated)?", "",
+				utils.FormatKeyValueList(wpConfig.Constants), false))
+			wp.ExtraEnv = utils.ParseKeyValueList(prompts.TextInput(
+				"Extra environment variables(KEY=value, comma separated)?", "",
+				utils.FormatKeyValueList(wpConfig.Env), false))
+		}
+
+		// Invalid resources from the config have to be corrected before deploying
+		customizeResources := false
+		if err := wp.ValidateResources(); err != nil {
+			fmt.Printf("⚠ Invalid resources in config: %s\n", err)
+			customizeResources = true
+		} else {
+			customizeResources = prompts.ConfirmationInput("Do you want to customize CPU and memory resources?", confirmation.No)
+		}
+
+		if customizeResources {
+			wp.CpuRequest = prompts.ValidatedTextInput("CPU request?", "", wp.CpuRequest, svc.ValidateQuantity)
+			wp.MemoryRequest = prompts.ValidatedTextInput("Memory request?", "", wp.MemoryRequest, svc.ValidateQuantity)
+			wp.CpuLimit = prompts.ValidatedTextInput("CPU limit(leave blank for no limit)?", "", wp.CpuLimit, svc.ValidateQuantity)
+			wp.MemoryLimit = prompts.ValidatedTextInput("Memory limit(leave blank for no limit)?", "", wp.MemoryLimit, svc.ValidateQuantity)
+		}
+
+		podSecurityLevel := svc.GetPodSecurityLevel(clientset, wp.Namespace)
+		hardenedDefault := confirmation.No
+		if podSecurityLevel == svc.POD_SECURITY_RESTRICTED {
+			hardenedDefault = confirmation.Yes
+		}
+
+		wp.Hardened = prompts.ConfirmationInput("Do you want to run the pod with a hardened security context?", hardenedDefault)
+		printPodSecurityWarning(wp, podSecurityLevel)
+
+		wp.NetworkPolicies = prompts.ConfirmationInput("Do you want to isolate the environment with network policies?", confirmation.No)
+		if wp.NetworkPolicies {
+			switch wp.Exposure {
+			case svc.EXPOSURE_INGRESS:
+				wp.IngressControllerNamespace = prompts.TextInput(
+					"Namespace of the ingress controller?", svc.WP_PLACEHOLDER_CONTROLLER_NAMESPACE,
+					getControllerNamespace(wp), true)
+			case svc.EXPOSURE_GATEWAY:
+				wp.IngressControllerNamespace = prompts.TextInput(
+					"Namespace of the gateway controller?", "", getControllerNamespace(wp), true)
+			}
+		}
+
+		wp.Bootstrap = prompts.ConfirmationInput("Do you want to install WordPress after deploy?", confirmation.No)
+		if wp.Bootstrap {
+			wp.SiteTitle = prompts.TextInput("Site title?", svc.WP_PLACEHOLDER_SITE_TITLE, "", true)
+			wp.AdminUser = prompts.TextInput("Admin user?", "", svc.WP_PLACEHOLDER_ADMIN_USER, true)
+			wp.AdminEmail = prompts.TextInput("Admin email?", svc.WP_PLACEHOLDER_ADMIN_EMAIL, "", true)
+			wp.Locale = prompts.TextInput("Site language?", "", svc.WP_PLACEHOLDER_LOCALE, true)
+			wp.PermalinkStructure = prompts.TextInput("Permalink structure?", "", svc.WP_PLACEHOLDER_PERMALINKS, false)
+			wp.Plugins = utils.SplitList(prompts.TextInput("Plugins to install and activate(comma separated)?", svc.WP_PLACEHOLDER_PLUGINS, "", false))
+			wp.Themes = utils.SplitList(prompts.TextInput("Themes to install(comma separated, first one is activated)?", svc.WP_PLACEHOLDER_THEMES, "", false))
+		}
+
+		if ConfirmDeploy(rawConfig) {
+			DeployWordPress(wp)
 		} else {
 			fmt.Println("Aborting...")
 		}
@@ -143,7 +313,176 @@ func CreateAppByType(clientset *kubernetes.Clientset, rawConfig api.Config, appC
 	}
 }
 
-func InitKubernetesConnection() (*kubernetes.Clientset, api.Config) {
+func ConfirmDeploy(rawConfig api.Config) bool {
+	return prompts.ConfirmationInput(
+		fmt.Sprintf(
+			"Are you sure you want to continue deploying to cluster: %s?",
+			rawConfig.Contexts[rawConfig.CurrentContext].Cluster),
+		confirmation.No)
+}
+
+func DeployWordPress(wp svc.WordPress) {
+	sepStyle := getSeparatorStyle()
+	fmt.Println(sepStyle.Render(""))
+	fmt.Print("Deploying resources to cluster...\n\n")
+
+	// Create namespace
+	namespace := wp.CreateNamespace(
+		"✔ Namespace %s created\n",
+		"⚠ Namespace already exists, continuing...\n")
+
+	// Quotas are only attached to namespaces created by kade
+	if namespace.Name != "" {
+		wp.CreateResourceQuota(
+			"✔ Resource quota %s created from profile %s\n",
+			"⚠ Resource quota already exists, continuing...\n",
+		)
+
+		wp.CreateLimitRange(
+			"✔ Limit range %s created\n",
+			"⚠ Limit range already exists, continuing...\n",
+		)
+	}
+
+	// Create network policies
+	wp.CreateNetworkPolicies(
+		"✔ Network policy %s created\n",
+		"⚠ Network policy %s already exists, continuing...\n",
+	)
+
+	// Create PVC
+	wp.CreatePvc(
+		"✔ PVC %s created\n",
+		"⚠ PVC already exists, continuing...\n")
+
+	wp.CreateDbPasswordSecret(
+		"✔ Database password secret %s created\n",
+		"⚠ Database password secret already exists, continuing...\n",
+	)
+
+	wp.CreateSaltsSecret(
+		"✔ WordPress keys and salts secret %s created\n",
+		"⚠ WordPress keys and salts secret already exists, continuing...\n",
+	)
+
+	if wp.Bootstrap {
+		wp.CreateAdminSecret(
+			"✔ WordPress admin secret %s created\n",
+			"⚠ WordPress admin secret already exists, reusing stored credentials...\n",
+		)
+	}
+
+	wp.CreateRegistryAuthSecret(
+		"✔ Container registry auth secret %s created\n",
+		"⚠ Container registry auth secret already exists, continuing...\n",
+		"⚠ Using existing image pull secret %s, skipping...\n",
+		"⚠ No container registry credentials provided, skipping...\n",
+	)
+
+	wp.CreatePhpConfigMap(
+		"✔ PHP config map %s created\n",
+		"⚠ PHP config map already exists, continuing...\n",
+		"⚠ No custom PHP settings provided, skipping...\n",
+	)
+
+	wp.CreateDeployment(
+		"✔ WordPress deployment %s created\n",
+		"⚠ WordPress deployment already exists, continuing...\n",
+	)
+
+	wp.CreateService(
+		"✔ WordPress service %s created\n",
+		"⚠ WordPress service already exists, continuing...\n",
+	)
+
+	wp.CreateBasicAuthSecret(
+		"✔ Basic auth secret %s created\n",
+		"⚠ Basic auth secret already exists, reusing stored credentials...\n",
+	)
+
+	wp.CreateTlsSecret(
+		"✔ TLS secret %s created\n",
+		"⚠ TLS secret already exists, continuing...\n",
+	)
+
+	wp.CreateTraefikMiddlewares(
+		"✔ Traefik middleware %s created\n",
+		"⚠ Traefik middleware %s already exists, continuing...\n",
+	)
+
+	wp.CreateIngress(
+		"✔ WordPress ingress %s created\n",
+		"⚠ WordPress ingress already exists, continuing...\n",
+		"⚠ Not exposed through an ingress, skipping...\n",
+	)
+
+	wp.CreateRedirectIngress(
+		"✔ WordPress redirect ingress %s created\n",
+		"⚠ WordPress redirect ingress already exists, continuing...\n",
+	)
+
+	wp.CreateHTTPRoute(
+		"✔ WordPress HTTP route %s created\n",
+		"⚠ WordPress HTTP route already exists, continuing...\n",
+		"⚠ Not exposed through a gateway, skipping...\n",
+	)
+
+	fmt.Println()
+	ready := PrintPreparingEnvironment(wp)
+
+	if wp.IsCertificateManaged() {
+		PrintWaitingForCertificate(wp)
+	}
+
+	bootstrapped := false
+	if wp.Bootstrap && ready {
+		bootstrapped = wp.BootstrapSite(
+			"✔ WordPress installed\n\n",
+			"⚠ WordPress is already installed, skipping...\n\n",
+			"⚠ WordPress installation failed: %s\n\n",
+		)
+	}
+
+	if wp.Exposure != svc.EXPOSURE_INTERNAL && ready {
+		fmt.Print("Verifying the environment URL...\n\n")
+
+		wp.VerifyDns(
+			"✔ %s resolves to %s\n",
+			"⚠ %s resolves to %s, but the load balancer address is %s\n",
+			"⚠ DNS lookup for %s failed: %s\n",
+		)
+
+		wp.VerifyHttp(
+			"✔ %s responded with %s\n",
+			"⚠ %s responded with %s\n",
+			"⚠ TLS error for %s: %s\n",
+			"⚠ Request to %s failed: %s\n",
+		)
+
+		fmt.Println()
+	}
+
+	if wp.Exposure == svc.EXPOSURE_INTERNAL {
+		PrintEnvironmentReady(fmt.Sprintf("Run `kade open %s` to access it on:\n%s", wp.Namespace, wp.GetDeploymentUrl()))
+	} else {
+		PrintEnvironmentReady(wp.GetDeploymentUrl())
+	}
+
+	if usage := wp.GetQuotaUsage(); len(usage) > 0 {
+		PrintQuotaUsage(usage)
+	}
+
+	if wp.BasicAuth {
+		PrintCredentials("Basic auth credentials for the environment:", wp.BasicAuthUser, wp.BasicAuthPass)
+	}
+
+	// Only show credentials when this run installed the site, reused ones are the ones installed
+	if bootstrapped {
+		PrintCredentials("WordPress admin credentials (only shown once):", wp.AdminUser, wp.AdminPass)
+	}
+}
+
+func InitKubernetesConnection() (*kubernetes.Clientset, *rest.Config, api.Config) {
 	kubeconfig := GetKubeconfig()
 
 	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
@@ -181,7 +520,251 @@ func InitKubernetesConnection() (*kubernetes.Clientset, api.Config) {
 	s.Stop()
 	fmt.Printf("Successfully connected to cluster: %s\n\n", rawConfig.Contexts[rawConfig.CurrentContext].Cluster)
 
-	return clientset, rawConfig
+	return clientset, config, rawConfig
+}
+
+func GetRancherProject(projects []svc.RancherProject) *svc.RancherProject {
+	options := []string{svc.RANCHER_NO_PROJECT}
+	for _, project := range projects {
+		options = append(options, project.String())
+	}
+
+	selected := prompts.SelectInput("Rancher project for the namespace?", options)
+
+	for _, project := range projects {
+		if project.String() == selected {
+			return &project
+		}
+	}
+
+	return nil
+}
+
+func GetRegistryAuth(wp *svc.WordPress, appConfig *config.Config) {
+	registry := svc.GetImageRegistry(wp.ContainerImage)
+	pullSecrets := svc.GetImagePullSecrets(wp.Clientset, wp.Namespace)
+
+	credentials, source := GetRegistryCredentials(appConfig, registry, len(pullSecrets) > 0)
+
+	// An existing pull secret is expected to cover every registry the pod needs
+	if source == svc.REGISTRY_AUTH_EXISTING_SECRET {
+		wp.ImagePullSecret = prompts.SelectInput("Image pull secret?", pullSecrets)
+		return
+	}
+
+	// Public images don't get a pull secret, so there is nothing to add registries to
+	if source == svc.REGISTRY_AUTH_NONE {
+		return
+	}
+
+	wp.RegistryCredentials = append(wp.RegistryCredentials, credentials)
+
+	additionalRegistries := utils.SplitList(prompts.TextInput(
+		"Additional registries to include in the pull secret, e.g. for sidecar images(comma separated, leave blank for none)?",
+		svc.WP_PLACEHOLDER_REGISTRIES, "", false))
+
+	registries := []string{registry}
+	for _, additionalRegistry := range additionalRegistries {
+		additionalRegistry = svc.NormalizeRegistry(additionalRegistry)
+		if slices.Contains(registries, additionalRegistry) {
+			continue
+		}
+		registries = append(registries, additionalRegistry)
+
+		credentials, source := GetRegistryCredentials(appConfig, additionalRegistry, false)
+		if source != svc.REGISTRY_AUTH_NONE {
+			wp.RegistryCredentials = append(wp.RegistryCredentials, credentials)
+		}
+	}
+}
+
+func GetRegistryCredentials(appConfig *config.Config, registry string, hasPullSecrets bool) (svc.RegistryCredentials, string) {
+	configured, hasConfigured := getConfiguredRegistry(appConfig, registry)
+	dockerCredentials, hasDockerCredentials := svc.GetDockerCredentials(registry)
+
+	// Credentials from the app config are preferred over the ones from docker
+	options := []string{}
+	if hasConfigured {
+		options = append(options, svc.REGISTRY_AUTH_APP_CONFIG)
+	}
+	if hasDockerCredentials {
+		options = append(options, svc.REGISTRY_AUTH_DOCKER_CONFIG)
+	}
+	if hasPullSecrets {
+		options = append(options, svc.REGISTRY_AUTH_EXISTING_SECRET)
+	}
+	options = append(options, svc.REGISTRY_AUTH_MANUAL, svc.REGISTRY_AUTH_NONE)
+
+	// Without known credentials the image is most likely public
+	if !hasConfigured && !hasDockerCredentials {
+		options = preferOption(options, svc.REGISTRY_AUTH_NONE, "")
+	}
+
+	source := prompts.SelectInput("Credentials for registry "+registry+"?", options)
+
+	switch source {
+	case svc.REGISTRY_AUTH_APP_CONFIG:
+		if configured.Pass == "" {
+			configured.Pass = prompts.PassWordInput("Container registry password for "+configured.User+"?", "", "", true)
+		}
+
+		return svc.RegistryCredentials{Registry: configured.Uri, Username: configured.User, Password: configured.Pass}, source
+	case svc.REGISTRY_AUTH_DOCKER_CONFIG:
+		fmt.Printf("Using docker config credentials for %s as %s\n", registry, dockerCredentials.Username)
+
+		return dockerCredentials, source
+	case svc.REGISTRY_AUTH_MANUAL:
+		return svc.RegistryCredentials{
+			Registry: svc.GetRegistryAuthKey(registry),
+			Username: prompts.TextInput("Container registry user?", "", "", true),
+			Password: prompts.PassWordInput("Container registry password?", "", "", true),
+		}, source
+	}
+
+	return svc.RegistryCredentials{}, source
+}
+
+func getConfiguredRegistry(appConfig *config.Config, registry string) (config.ContainerRegistryConfig, bool) {
+	for _, configured := range appConfig.GetContainerRegistries() {
+		// Credentials without a URI are for Docker Hub
+		if configured.Uri == "" {
+			configured.Uri = svc.DOCKER_HUB_AUTH_KEY
+		}
+
+		if configured.User != "" && svc.NormalizeRegistry(configured.Uri) == registry {
+			return configured, true
+		}
+	}
+
+	return config.ContainerRegistryConfig{}, false
+}
+
+func VerifyContainerImage(wp *svc.WordPress) {
+	ref := svc.ParseImageReference(wp.ContainerImage)
+
+	// Offer recent tags when the image was entered without one
+	if ref.Tag == "" && ref.Digest == "" {
+		if tags, err := wp.GetImageTags(); err == nil && len(tags) > 0 {
+			wp.ContainerImage = ref.WithTag(prompts.SelectInput("Tag to deploy for "+ref.Name+"?", tags))
+		}
+	}
+
+	for {
+		platforms, err := wp.VerifyImage()
+
+		if errors.Is(err, svc.ErrImageNotFound) {
+			fmt.Printf("⚠ Image %s was not found in the registry\n", wp.ContainerImage)
+
+			tags, _ := wp.GetImageTags()
+			if len(tags) == 0 {
+				if !prompts.ConfirmationInput("Do you want to continue anyway?", confirmation.No) {
+					fmt.Println("Aborting...")
+					os.Exit(0)
+				}
+				return
+			}
+
+			wp.ContainerImage = svc.ParseImageReference(wp.ContainerImage).WithTag(
+				prompts.SelectInput("Tag to deploy instead?", tags))
+			continue
+		}
+
+		// Registries that can't be reached from here may still be reachable from the cluster
+		if err != nil {
+			fmt.Printf("⚠ Could not verify image %s: %s\n", wp.ContainerImage, err)
+			return
+		}
+
+		if printImagePlatforms(*wp, platforms) {
+			if !prompts.ConfirmationInput("Do you want to continue anyway?", confirmation.No) {
+				fmt.Println("Aborting...")
+				os.Exit(0)
+			}
+		}
+
+		return
+	}
+}
+
+func GetQuotaProfile(appConfig *config.Config) *svc.QuotaProfile {
+	profiles := appConfig.GetQuotaProfiles()
+
+	names := []string{}
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	selected := prompts.SelectInput(
+		"Resource quota profile for new namespaces?",
+		append([]string{svc.QUOTA_NO_PROFILE}, names...))
+
+	return newQuotaProfile(appConfig, selected)
+}
+
+func newQuotaProfile(appConfig *config.Config, name string) *svc.QuotaProfile {
+	profileConfig, ok := appConfig.GetQuotaProfiles()[name]
+	if !ok {
+		return nil
+	}
+
+	profile := svc.QuotaProfile{
+		Name:                 name,
+		RequestsCpu:          profileConfig.RequestsCpu,
+		RequestsMemory:       profileConfig.RequestsMemory,
+		LimitsCpu:            profileConfig.LimitsCpu,
+		LimitsMemory:         profileConfig.LimitsMemory,
+		RequestsStorage:      profileConfig.RequestsStorage,
+		Pods:                 profileConfig.Pods,
+		DefaultRequestCpu:    profileConfig.DefaultRequestCpu,
+		DefaultRequestMemory: profileConfig.DefaultRequestMemory,
+		DefaultLimitCpu:      profileConfig.DefaultLimitCpu,
+		DefaultLimitMemory:   profileConfig.DefaultLimitMemory,
+	}
+
+	if err := svc.ValidateQuotaProfile(profile); err != nil {
+		log.Fatal(err)
+	}
+
+	return &profile
+}
+
+func GetHostnameSuggestion(wp svc.WordPress, appConfig *config.Config) string {
+	hostnameConfig := appConfig.Global.Hostname
+
+	data := svc.HostnameData{
+		Namespace:      wp.Namespace,
+		DeploymentName: wp.DeploymentName,
+		BaseDomain:     hostnameConfig.BaseDomain,
+	}
+
+	// Wildcard DNS services need the IP of the ingress controller or gateway
+	if svc.IsWildcardDnsDomain(hostnameConfig.BaseDomain) || strings.Contains(hostnameConfig.Template, ".IP") {
+		data.IP = wp.GetEntryPointIP()
+		if data.IP == "" {
+			return ""
+		}
+
+		data.IPDashed = strings.ReplaceAll(data.IP, ".", "-")
+	}
+
+	return svc.RenderHostname(hostnameConfig.Template, data)
+}
+
+// Move the preferred option first so that it is selected by default
+func preferOption(options []string, preferred, suffix string) []string {
+	if preferred == "" {
+		return options
+	}
+
+	for i, option := range options {
+		if strings.TrimSuffix(option, suffix) == preferred {
+			reordered := append([]string{option}, options[:i]...)
+			return append(reordered, options[i+1:]...)
+		}
+	}
+
+	return options
 }
 
 func GetKubeconfig() string {
@@ -227,21 +810,65 @@ func PrintNotImplemented() {
 	fmt.Println(style.Render("Not implemented yet, come back later!"))
 }
 
-func PrintPreparingEnvironment(wp svc.WordPress) {
+func PrintPreparingEnvironment(wp svc.WordPress) bool {
 	p := spinner.New(spinner.CharSets[26], 250*time.Millisecond)
 	p.Prefix = "Preparing the environment "
 	p.Start()
 
+	ready := true
+	multiAttachReported := false
 	count := 0
 	for !wp.IsDeploymentReady() {
+		if !multiAttachReported {
+			if message := wp.GetMultiAttachError(); message != "" {
+				p.Stop()
+				fmt.Print(
+					"⚠ The uploads volume is still attached to another node:\n" + message + "\n" +
+						"This happens when a ReadWriteOnce volume is shared by pods on different nodes.\n" +
+						"Wait for the old pod to terminate, or use ReadWriteMany storage for the uploads volume.\n\n")
+				p.Start()
+				multiAttachReported = true
+			}
+		}
+
+		time.Sleep(5 * time.Second)
+		count++
+		if count == 36 {
+			ready = false
+			break
+		}
+	}
+
+	p.Stop()
+
+	if !ready {
+		fmt.Print("⚠ The environment did not pass its readiness checks in time, it may still be starting...\n\n")
+	}
+
+	return ready
+}
+
+func PrintWaitingForCertificate(wp svc.WordPress) {
+	p := spinner.New(spinner.CharSets[26], 250*time.Millisecond)
+	p.Prefix = "Waiting for the TLS certificate "
+	p.Start()
+
+	ready := true
+	count := 0
+	for !wp.IsCertificateReady() {
 		time.Sleep(5 * time.Second)
 		count++
-		if count == 11 {
+		if count == 36 {
+			ready = false
 			break
 		}
 	}
 
 	p.Stop()
+
+	if !ready {
+		fmt.Print("⚠ The TLS certificate is not ready yet, HTTPS may not work until it has been issued...\n\n")
+	}
 }
 
 func PrintEnvironmentReady(url string) {
@@ -249,6 +876,18 @@ func PrintEnvironmentReady(url string) {
 	fmt.Println(style.Render("✔ Environment ready:\n\n" + url))
 }
 
+func PrintQuotaUsage(usage []string) {
+	style := getContainerStyle()
+	fmt.Println(style.Render("Resource quota usage:\n\n" + strings.Join(usage, "\n")))
+}
+
+func PrintCredentials(title, user, pass string) {
+	style := getContainerStyle()
+	fmt.Println(style.Render(
+		title + "\n\n" +
+			"User: " + user + "\nPassword: " + pass))
+}
+
 func getContainerStyle() lipgloss.Style {
 	return lipgloss.NewStyle().
 		Bold(true).
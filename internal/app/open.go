@@ -0,0 +1,77 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/adde/kade/internal/prompts"
+	"github.com/adde/kade/internal/svc"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Open(args []string) {
+	var localPort int
+
+	openFlags := flag.NewFlagSet("open", flag.ExitOnError)
+	openFlags.IntVar(&localPort, "port", svc.K8S_PORT_FORWARD_LOCAL_PORT, "local port to forward to the environment")
+	openFlags.IntVar(&localPort, "p", svc.K8S_PORT_FORWARD_LOCAL_PORT, "alias for local port to forward to the environment")
+	openFlags.Parse(args)
+
+	namespace := openFlags.Arg(0)
+	if namespace == "" {
+		fmt.Println("Usage: kade open [--port <port>] <namespace>")
+		os.Exit(1)
+	}
+
+	clientset, restConfig, _ := InitKubernetesConnection()
+
+	services, err := svc.GetManagedServices(clientset, namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	service := GetManagedService(services, namespace)
+
+	fmt.Printf("Forwarding service %s in namespace %s...\n", service.Name, namespace)
+
+	err = svc.PortForward(
+		restConfig,
+		clientset,
+		service,
+		localPort,
+		"✔ Environment available on %s (press Ctrl+C to stop)\n",
+	)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func GetManagedService(services []corev1.Service, namespace string) corev1.Service {
+	if len(services) == 0 {
+		fmt.Printf("No kade managed service found in namespace %s\n", namespace)
+		os.Exit(1)
+	}
+
+	if len(services) == 1 {
+		return services[0]
+	}
+
+	names := []string{}
+	for _, service := range services {
+		names = append(names, service.Name)
+	}
+
+	selected := prompts.SelectInput("Which service do you want to open?", names)
+	fmt.Println()
+
+	for _, service := range services {
+		if service.Name == selected {
+			return service
+		}
+	}
+
+	return services[0]
+}
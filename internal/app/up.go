@@ -0,0 +1,275 @@
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/adde/kade/internal/config"
+	"github.com/adde/kade/internal/prompts"
+	"github.com/adde/kade/internal/svc"
+	"github.com/adde/kade/internal/utils"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func Up(args []string) {
+	var projectFile string
+	var skipConfirm bool
+
+	upFlags := flag.NewFlagSet("up", flag.ExitOnError)
+	upFlags.StringVar(&projectFile, "file", config.PROJECT_CONFIG_FILE, "project config file to deploy")
+	upFlags.StringVar(&projectFile, "f", config.PROJECT_CONFIG_FILE, "alias for project config file to deploy")
+	upFlags.BoolVar(&skipConfirm, "yes", false, "deploy without asking for confirmation")
+	upFlags.BoolVar(&skipConfirm, "y", false, "alias for deploy without asking for confirmation")
+	upFlags.StringVar(&profileName, "profile", profileName, "config profile to use instead of the one matching the kube context")
+	upFlags.Parse(args)
+
+	project, err := config.ReadProjectConfig(projectFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			fmt.Printf("No %s found in the current directory\n", projectFile)
+			os.Exit(1)
+		}
+		log.Fatal(err)
+	}
+
+	if !skipVersionCheck {
+		PrintVersionInfo()
+	}
+
+	PrintHeader()
+
+	clientset, restConfig, rawConfig := InitKubernetesConnection()
+	appConfig := GetAppConfig(rawConfig)
+	appConfig.ApplyProject(project)
+
+	fmt.Printf("Deploying %s from %s\n\n", project.App, projectFile)
+
+	switch project.App {
+	case "WordPress":
+		wp := GetWordPressFromProject(clientset, restConfig, appConfig, project)
+
+		if skipConfirm || ConfirmDeploy(rawConfig) {
+			DeployWordPress(wp)
+		} else {
+			fmt.Println("Aborting...")
+		}
+	default:
+		PrintNotImplemented()
+	}
+}
+
+func GetWordPressFromProject(clientset *kubernetes.Clientset, restConfig *rest.Config, appConfig *config.Config, project *config.ProjectConfig) svc.WordPress {
+	wp := svc.WordPress{
+		Namespace:      project.Namespace,
+		DeploymentName: utils.ValueOrDefault(project.DeploymentName, svc.WP_PLACEHOLDER_DEPLOYMENT),
+		UploadsVolSize: utils.ValueOrDefault(project.UploadsVolumeSize, svc.WP_PLACEHOLDER_WP_UPLOADS),
+		Clientset:      clientset,
+		RestConfig:     restConfig,
+	}
+
+	if err := svc.ValidateVolumeSize(wp.UploadsVolSize); err != nil {
+		log.Fatalf("uploadsVolumeSize: %s", err)
+	}
+
+	// Projects and quotas are only assigned when kade creates the namespace
+	if !svc.NamespaceExists(clientset, wp.Namespace) {
+		if project.RancherProject != "" {
+			projects, err := svc.GetRancherProjects(restConfig)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			wp.RancherProject = findRancherProject(projects, project.RancherProject)
+		}
+
+		if project.QuotaProfile != "" {
+			wp.QuotaProfile = newQuotaProfile(appConfig, project.QuotaProfile)
+			if wp.QuotaProfile == nil {
+				log.Fatalf("quota profile %s does not exist", project.QuotaProfile)
+			}
+		}
+	}
+
+	// An empty storage class uses the cluster default
+	wp.StorageClass = appConfig.Global.StorageClass
+	wp.UploadsAccessMode = utils.ValueOrDefault(project.AccessMode, svc.K8S_ACCESS_MODES[0])
+	if !slices.Contains(svc.K8S_ACCESS_MODES, wp.UploadsAccessMode) {
+		log.Fatalf("accessMode must be one of %s", strings.Join(svc.K8S_ACCESS_MODES, ", "))
+	}
+
+	wp.ContainerImage = project.Image
+	wp.ImagePullSecret = project.ImagePullSecret
+
+	// Registries listed in the project are included in the pull secret, e.g. for sidecar images
+	if wp.ImagePullSecret == "" {
+		registries := []string{svc.GetImageRegistry(wp.ContainerImage)}
+		for _, configured := range project.ContainerRegistries {
+			if registry := svc.NormalizeRegistry(configured.Uri); !slices.Contains(registries, registry) {
+				registries = append(registries, registry)
+			}
+		}
+
+		for _, registry := range registries {
+			if credentials, ok := getRegistryCredentialsFromConfig(appConfig, registry); ok {
+				wp.RegistryCredentials = append(wp.RegistryCredentials, credentials)
+			}
+		}
+	}
+
+	platforms, err := wp.VerifyImage()
+	switch {
+	case errors.Is(err, svc.ErrImageNotFound):
+		tags, _ := wp.GetImageTags()
+		log.Fatalf("image %s was not found in the registry, recent tags: %s", wp.ContainerImage, strings.Join(tags, ", "))
+	case err != nil:
+		fmt.Printf("⚠ Could not verify image %s: %s\n", wp.ContainerImage, err)
+	default:
+		printImagePlatforms(wp, platforms)
+	}
+
+	switch project.Exposure {
+	case config.PROJECT_EXPOSURE_GATEWAY:
+		wp.Exposure = svc.EXPOSURE_GATEWAY
+		wp.GatewayNamespace, wp.GatewayName, _ = strings.Cut(project.Gateway, "/")
+	case config.PROJECT_EXPOSURE_INTERNAL:
+		wp.Exposure = svc.EXPOSURE_INTERNAL
+	default:
+		wp.Exposure = svc.EXPOSURE_INGRESS
+	}
+
+	if wp.Exposure == svc.EXPOSURE_INGRESS {
+		wp.IngressClass = appConfig.Global.IngressClass
+		if ingressClasses := svc.GetIngressClasses(clientset); wp.IngressClass == "" && len(ingressClasses) > 0 &&
+			strings.HasSuffix(ingressClasses[0], svc.K8S_DEFAULT_INGRESS_CLASS_SUFFIX) {
+			wp.IngressClass = strings.TrimSuffix(ingressClasses[0], svc.K8S_DEFAULT_INGRESS_CLASS_SUFFIX)
+		}
+
+		wp.IngressController = svc.GetIngressController(clientset, wp.IngressClass)
+	}
+
+	if wp.Exposure != svc.EXPOSURE_INTERNAL {
+		wp.Hostname = GetHostnameSuggestion(wp, appConfig)
+		if wp.Hostname == "" {
+			log.Fatal("hostname could not be rendered, set hostname.template or hostname.baseDomain")
+		}
+
+		wp.Aliases = project.Aliases
+		wp.RedirectAliases = project.RedirectAliases && wp.Exposure == svc.EXPOSURE_INGRESS
+		wp.IngressTls = project.Tls
+	}
+
+	if err := wp.ValidateGateway(); err != nil {
+		log.Fatal(err)
+	}
+
+	if wp.IngressTls && wp.Exposure == svc.EXPOSURE_INGRESS {
+		if project.TlsSecret != "" {
+			wp.TlsSource = svc.TLS_SOURCE_EXISTING_SECRET
+			wp.TlsSecretName = project.TlsSecret
+		} else {
+			wp.TlsSource = svc.TLS_SOURCE_CERT_MANAGER
+			wp.IssuerKind, wp.IssuerName = parseIssuer(appConfig.Global.Issuer)
+		}
+	}
+
+	if wp.Exposure == svc.EXPOSURE_INGRESS {
+		// Fail instead of exposing an environment that was meant to be protected
+		if (project.BasicAuthUser != "" || len(project.AllowedSourceRanges) > 0) && !wp.SupportsAccessControl() {
+			log.Fatal("basicAuthUser and allowedSourceRanges are only supported with nginx and Traefik ingress controllers")
+		}
+
+		wp.BasicAuth = project.BasicAuthUser != ""
+		wp.BasicAuthUser = project.BasicAuthUser
+
+		if err := svc.ValidateSourceRanges(strings.Join(project.AllowedSourceRanges, ",")); err != nil {
+			log.Fatalf("allowedSourceRanges: %s", err)
+		}
+		wp.AllowedSourceRanges = project.AllowedSourceRanges
+	}
+
+	wp.DatabaseHost = appConfig.Global.Database.Host
+	wp.DatabaseName = project.Database.Name
+	wp.DatabaseUser = appConfig.Global.Database.User
+	if wp.DatabaseHost == "" || wp.DatabaseName == "" || wp.DatabaseUser == "" {
+		log.Fatal("database host, name and user are required")
+	}
+
+	wp.DatabasePass = appConfig.Global.Database.Pass
+	if wp.DatabasePass == "" {
+		wp.DatabasePass = prompts.PassWordInput("Database password?", "", "", true)
+	}
+
+	setWordPressDefaults(&wp, appConfig.Global.WordPress)
+	wp.Debug = project.Debug
+
+	if err := wp.ValidateResources(); err != nil {
+		log.Fatalf("wordpress.resources: %s", err)
+	}
+
+	// Follow the Pod Security Standard of the namespace unless the project decides
+	podSecurityLevel := svc.GetPodSecurityLevel(clientset, wp.Namespace)
+	wp.Hardened = podSecurityLevel == svc.POD_SECURITY_RESTRICTED
+	if project.Hardened != nil {
+		wp.Hardened = *project.Hardened
+	}
+
+	printPodSecurityWarning(wp, podSecurityLevel)
+
+	wp.NetworkPolicies = project.NetworkPolicies
+	if wp.NetworkPolicies {
+		wp.IngressControllerNamespace = getControllerNamespace(wp)
+
+		if wp.IngressControllerNamespace == "" && wp.Exposure != svc.EXPOSURE_INTERNAL {
+			log.Fatal("namespace of the ingress controller could not be found, network policies can not be created")
+		}
+	}
+
+	if bootstrap := project.Bootstrap; bootstrap != nil {
+		wp.Bootstrap = true
+		wp.SiteTitle = utils.ValueOrDefault(bootstrap.SiteTitle, wp.DeploymentName)
+		wp.AdminUser = utils.ValueOrDefault(bootstrap.AdminUser, svc.WP_PLACEHOLDER_ADMIN_USER)
+		wp.AdminEmail = utils.ValueOrDefault(bootstrap.AdminEmail, svc.WP_PLACEHOLDER_ADMIN_EMAIL)
+		wp.Locale = utils.ValueOrDefault(bootstrap.Locale, svc.WP_PLACEHOLDER_LOCALE)
+		wp.PermalinkStructure = utils.ValueOrDefault(bootstrap.PermalinkStructure, svc.WP_PLACEHOLDER_PERMALINKS)
+		wp.Plugins = bootstrap.Plugins
+		wp.Themes = bootstrap.Themes
+	}
+
+	return wp
+}
+
+// Looks up credentials without prompting, except for passwords missing from the app config
+func getRegistryCredentialsFromConfig(appConfig *config.Config, registry string) (svc.RegistryCredentials, bool) {
+	if configured, ok := getConfiguredRegistry(appConfig, registry); ok {
+		if configured.Pass == "" {
+			configured.Pass = prompts.PassWordInput("Container registry password for "+registry+"?", "", "", true)
+		}
+
+		return svc.RegistryCredentials{Registry: configured.Uri, Username: configured.User, Password: configured.Pass}, true
+	}
+
+	if credentials, ok := svc.GetDockerCredentials(registry); ok {
+		fmt.Printf("Using docker config credentials for %s as %s\n", registry, credentials.Username)
+		return credentials, true
+	}
+
+	return svc.RegistryCredentials{}, false
+}
+
+func findRancherProject(projects []svc.RancherProject, name string) *svc.RancherProject {
+	for _, project := range projects {
+		if project.DisplayName == name || project.GetProjectId() == name {
+			return &project
+		}
+	}
+
+	log.Fatalf("rancher project %s does not exist", name)
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adde/kade/internal/config"
+	"github.com/adde/kade/internal/svc"
+	"github.com/adde/kade/internal/utils"
+)
+
+// Steps shared by the interactive flow and kade up, so both deploy the same way
+
+// Issuers without a kind are assumed to be cluster issuers
+func parseIssuer(issuer string) (string, string) {
+	if kind, name, found := strings.Cut(issuer, "/"); found {
+		return kind, name
+	}
+
+	return svc.K8S_CLUSTER_ISSUER_KIND, utils.ValueOrDefault(issuer, svc.K8S_CLUSTER_ISSUER_NAME)
+}
+
+func setWordPressDefaults(wp *svc.WordPress, wpConfig config.WordPressConfig) {
+	wp.ExtraEnv = wpConfig.Env
+	wp.Constants = wpConfig.Constants
+	wp.ConfigExtra = wpConfig.ConfigExtra
+	wp.PhpIni = map[string]string{}
+	for key, value := range wpConfig.PhpIni {
+		wp.PhpIni[key] = value
+	}
+
+	wp.CpuRequest = utils.ValueOrDefault(wpConfig.Resources.CpuRequest, svc.WP_DEFAULT_CPU_REQUEST)
+	wp.MemoryRequest = utils.ValueOrDefault(wpConfig.Resources.MemoryRequest, svc.WP_DEFAULT_MEMORY_REQUEST)
+	wp.CpuLimit = utils.ValueOrDefault(wpConfig.Resources.CpuLimit, svc.WP_DEFAULT_CPU_LIMIT)
+	wp.MemoryLimit = utils.ValueOrDefault(wpConfig.Resources.MemoryLimit, svc.WP_DEFAULT_MEMORY_LIMIT)
+}
+
+// Returns true when the image isn't built for some of the cluster nodes
+func printImagePlatforms(wp svc.WordPress, platforms []string) bool {
+	fmt.Printf("✔ Image %s found (%s)\n", wp.ContainerImage,
+		utils.ValueOrDefault(strings.Join(platforms, ", "), "platform unknown"))
+
+	unsupported := wp.GetUnsupportedPlatforms(platforms)
+	if len(unsupported) > 0 {
+		fmt.Printf("⚠ Image %s is not built for %s, pods scheduled on those nodes will fail to start\n",
+			wp.ContainerImage, strings.Join(unsupported, ", "))
+	}
+
+	return len(unsupported) > 0
+}
+
+func printPodSecurityWarning(wp svc.WordPress, podSecurityLevel string) {
+	if !wp.Hardened && podSecurityLevel == svc.POD_SECURITY_RESTRICTED {
+		fmt.Printf("⚠ Namespace %s enforces the restricted Pod Security Standard, the pod will be rejected without a hardened security context\n", wp.Namespace)
+	}
+}
+
+// Network policies allow traffic from the namespace of the ingress or gateway controller
+func getControllerNamespace(wp svc.WordPress) string {
+	switch wp.Exposure {
+	case svc.EXPOSURE_INGRESS:
+		return svc.GetIngressControllerNamespace(wp.Clientset, wp.IngressController)
+	case svc.EXPOSURE_GATEWAY:
+		return wp.GatewayNamespace
+	}
+
+	return ""
+}
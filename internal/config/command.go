@@ -0,0 +1,304 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/adde/kade/internal/prompts"
+	"github.com/erikgeiser/promptkit/confirmation"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	CONFIG_MASKED_SECRET = "********"
+)
+
+func Command(args []string) {
+	if len(args) == 0 {
+		printCommandUsage()
+		os.Exit(1)
+	}
+
+	filename := getUserHomeDir() + CONFIG_PATH
+
+	switch args[0] {
+	case "create":
+		CreateConfig()
+	case "show":
+		showConfig(filename)
+	case "get":
+		if len(args) != 2 {
+			fmt.Println("Usage: kade config get <key>")
+			os.Exit(1)
+		}
+		getConfigValue(filename, args[1])
+	case "set":
+		if len(args) != 3 {
+			fmt.Println("Usage: kade config set <key> <value>")
+			os.Exit(1)
+		}
+		setConfigValue(filename, args[1], args[2])
+	case "edit":
+		editConfig(filename)
+	case "validate":
+		errors := validateConfig(readConfigFile(filename))
+		if len(errors) > 0 {
+			printValidationErrors(errors)
+			os.Exit(1)
+		}
+		fmt.Println("✔ Config file is valid")
+	case "migrate":
+		migrateConfigFile(filename)
+	default:
+		printCommandUsage()
+		os.Exit(1)
+	}
+}
+
+func printCommandUsage() {
+	fmt.Println("Usage: kade config <command>")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  create               create a new config file")
+	fmt.Println("  show                 print the config file with passwords masked")
+	fmt.Println("  get <key>            print a value, e.g. global.database.host")
+	fmt.Println("  set <key> <value>    set a value and validate the result")
+	fmt.Println("  edit                 open the config file in $EDITOR and validate it")
+	fmt.Println("  validate             check the config file for errors")
+	fmt.Println("  migrate              upgrade the config file to the current version")
+}
+
+func readConfigFile(filename string) []byte {
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			fmt.Println("Config file does not exist, create it with: kade config create")
+			os.Exit(1)
+		}
+		log.Fatal(err)
+	}
+
+	return buf
+}
+
+func readConfigDocument(filename string) *yaml.Node {
+	document, err := parseDocument(readConfigFile(filename))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return document
+}
+
+func showConfig(filename string) {
+	buf, err := encodeDocument(maskSecrets(readConfigDocument(filename)))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Print(string(buf))
+}
+
+// Plain text passwords are masked, references are safe to show as they are
+func maskSecrets(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.MappingNode {
+		for i := 1; i < len(node.Content); i += 2 {
+			key, value := node.Content[i-1], node.Content[i]
+			if key.Value == "pass" && value.Kind == yaml.ScalarNode {
+				if value.Value != "" && !isSecretReference(value.Value) {
+					value.Value, value.Tag, value.Style = CONFIG_MASKED_SECRET, "!!str", 0
+				}
+				continue
+			}
+
+			maskSecrets(value)
+		}
+
+		return node
+	}
+
+	for _, child := range node.Content {
+		maskSecrets(child)
+	}
+
+	return node
+}
+
+func isSecretReference(value string) bool {
+	return secretEnvReference.MatchString(value) || strings.HasPrefix(value, SECRET_COMMAND_PREFIX)
+}
+
+func getConfigValue(filename, key string) {
+	node := findNode(readConfigDocument(filename).Content[0], splitKey(key)...)
+	if node == nil {
+		fmt.Printf("Key %s is not set\n", key)
+		os.Exit(1)
+	}
+
+	if node.Kind == yaml.ScalarNode {
+		fmt.Println(node.Value)
+		return
+	}
+
+	buf, err := encodeDocument(maskSecrets(node))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(string(buf))
+}
+
+func setConfigValue(filename, key, value string) {
+	document, err := parseDocument([]byte{})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if fileExists(filename) {
+		document = readConfigDocument(filename)
+	}
+
+	path := splitKey(key)
+	setNode(document.Content[0], path, parseConfigValue(path, value))
+
+	buf, err := encodeDocument(document)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if errors := validateConfig(buf); len(errors) > 0 {
+		printValidationErrors(errors)
+		fmt.Println("Config file was not changed")
+		os.Exit(1)
+	}
+
+	if err := writeConfigFile(filename, buf); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("✔ Set %s\n", key)
+}
+
+// String fields are stored exactly as given, so passwords like 0123 or yes are kept as they
+// are. Other values are parsed as YAML so numbers, booleans and lists keep their type.
+func parseConfigValue(path []string, value string) *yaml.Node {
+	str := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+
+	if fieldType, ok := getFieldType(reflect.TypeOf(Config{}), path); ok && fieldType.Kind() == reflect.String {
+		return str
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &document); err != nil || len(document.Content) == 0 {
+		return str
+	}
+
+	return document.Content[0]
+}
+
+func editConfig(filename string) {
+	buf := []byte{}
+	if fileExists(filename) {
+		buf = readConfigFile(filename)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		log.Fatal(err)
+	}
+
+	// Edit a copy so an invalid config never replaces the current one
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "config-*.yml")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf); err != nil {
+		log.Fatal(err)
+	}
+	tmp.Close()
+
+	for {
+		if err := runEditor(tmp.Name()); err != nil {
+			log.Fatal(err)
+		}
+
+		edited, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		errors := validateConfig(edited)
+		if len(errors) == 0 {
+			if err := writeConfigFile(filename, edited); err != nil {
+				log.Fatal(err)
+			}
+
+			fmt.Println("✔ Config file saved")
+			return
+		}
+
+		printValidationErrors(errors)
+
+		if !prompts.ConfirmationInput("Open the editor again to fix the errors?", confirmation.Yes) {
+			fmt.Println("Changes discarded, config file was not changed")
+			os.Exit(1)
+		}
+	}
+}
+
+func runEditor(filename string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+
+	if editor == "" {
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		} else {
+			editor = "vi"
+		}
+	}
+
+	// The editor may include arguments, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], filename)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+func printValidationErrors(errors []ValidationError) {
+	fmt.Printf("✘ Config file has %d error(s):\n", len(errors))
+	for _, err := range errors {
+		fmt.Printf("  %s\n", err.Error())
+	}
+}
+
+func migrateConfigFile(filename string) {
+	buf := readConfigFile(filename)
+
+	migrated, fromVersion, err := migrateConfig(buf)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if fromVersion == CONFIG_VERSION {
+		fmt.Printf("Config file is already at version %d\n", CONFIG_VERSION)
+		return
+	}
+
+	if err := writeMigratedConfig(filename, buf, migrated); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("✔ Migrated config file from version %d to %d, backup saved to %s.bak\n", fromVersion, CONFIG_VERSION, filename)
+}
@@ -5,10 +5,11 @@ import (
 	"log"
 	"os"
 	"os/user"
-	"strings"
+	"path/filepath"
+	"sort"
 
 	"github.com/adde/kade/internal/prompts"
-	"gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -16,34 +17,191 @@ const (
 )
 
 type DatabaseConfig struct {
-	Host string `yaml:"host"`
-	User string `yaml:"user"`
-	Pass string `yaml:"pass"`
+	Host string `yaml:"host,omitempty"`
+	User string `yaml:"user,omitempty"`
+	Pass string `yaml:"pass,omitempty"`
 }
 
 type ContainerRegistryConfig struct {
-	Uri  string `yaml:"uri"`
-	User string `yaml:"user"`
-	Pass string `yaml:"pass"`
+	Uri  string `yaml:"uri,omitempty"`
+	User string `yaml:"user,omitempty"`
+	Pass string `yaml:"pass,omitempty"`
+}
+
+type ResourcesConfig struct {
+	CpuRequest    string `yaml:"cpuRequest,omitempty"`
+	MemoryRequest string `yaml:"memoryRequest,omitempty"`
+	CpuLimit      string `yaml:"cpuLimit,omitempty"`
+	MemoryLimit   string `yaml:"memoryLimit,omitempty"`
+}
+
+type WordPressConfig struct {
+	Env         map[string]string `yaml:"env,omitempty"`
+	Constants   map[string]string `yaml:"constants,omitempty"`
+	ConfigExtra string            `yaml:"configExtra,omitempty"`
+	PhpIni      map[string]string `yaml:"phpIni,omitempty"`
+	Resources   ResourcesConfig   `yaml:"resources,omitempty"`
+}
+
+type HostnameConfig struct {
+	BaseDomain string `yaml:"baseDomain,omitempty"`
+	Template   string `yaml:"template,omitempty"`
+}
+
+type QuotaProfileConfig struct {
+	RequestsCpu          string `yaml:"requestsCpu,omitempty"`
+	RequestsMemory       string `yaml:"requestsMemory,omitempty"`
+	LimitsCpu            string `yaml:"limitsCpu,omitempty"`
+	LimitsMemory         string `yaml:"limitsMemory,omitempty"`
+	RequestsStorage      string `yaml:"requestsStorage,omitempty"`
+	Pods                 string `yaml:"pods,omitempty"`
+	DefaultRequestCpu    string `yaml:"defaultRequestCpu,omitempty"`
+	DefaultRequestMemory string `yaml:"defaultRequestMemory,omitempty"`
+	DefaultLimitCpu      string `yaml:"defaultLimitCpu,omitempty"`
+	DefaultLimitMemory   string `yaml:"defaultLimitMemory,omitempty"`
 }
 
 type GlobalConfig struct {
-	ContainerRegistry ContainerRegistryConfig `yaml:"containerRegistry"`
-	Database          DatabaseConfig          `yaml:"database"`
+	ContainerRegistry   ContainerRegistryConfig       `yaml:"containerRegistry"`
+	ContainerRegistries []ContainerRegistryConfig     `yaml:"containerRegistries,omitempty"`
+	Database            DatabaseConfig                `yaml:"database"`
+	WordPress           WordPressConfig               `yaml:"wordpress,omitempty"`
+	Hostname            HostnameConfig                `yaml:"hostname,omitempty"`
+	QuotaProfiles       map[string]QuotaProfileConfig `yaml:"quotaProfiles,omitempty"`
+	IngressClass        string                        `yaml:"ingressClass,omitempty"`
+	Issuer              string                        `yaml:"issuer,omitempty"`
+	StorageClass        string                        `yaml:"storageClass,omitempty"`
+}
+
+type ProfileConfig struct {
+	Contexts            []string                  `yaml:"contexts,omitempty"`
+	ContainerRegistry   ContainerRegistryConfig   `yaml:"containerRegistry,omitempty"`
+	ContainerRegistries []ContainerRegistryConfig `yaml:"containerRegistries,omitempty"`
+	Database            DatabaseConfig            `yaml:"database,omitempty"`
+	IngressClass        string                    `yaml:"ingressClass,omitempty"`
+	Issuer              string                    `yaml:"issuer,omitempty"`
+	StorageClass        string                    `yaml:"storageClass,omitempty"`
+	Hostname            HostnameConfig            `yaml:"hostname,omitempty"`
 }
 
 type Config struct {
-	Global GlobalConfig `yaml:"global"`
+	Version  int                      `yaml:"version"`
+	Global   GlobalConfig             `yaml:"global"`
+	Profiles map[string]ProfileConfig `yaml:"profiles,omitempty"`
+}
+
+var DefaultQuotaProfiles = map[string]QuotaProfileConfig{
+	"small": {
+		RequestsCpu: "500m", RequestsMemory: "512Mi", LimitsCpu: "1", LimitsMemory: "1Gi",
+		RequestsStorage: "5Gi", Pods: "5",
+		DefaultRequestCpu: "100m", DefaultRequestMemory: "128Mi", DefaultLimitCpu: "500m", DefaultLimitMemory: "256Mi",
+	},
+	"medium": {
+		RequestsCpu: "1", RequestsMemory: "1Gi", LimitsCpu: "2", LimitsMemory: "2Gi",
+		RequestsStorage: "20Gi", Pods: "10",
+		DefaultRequestCpu: "100m", DefaultRequestMemory: "256Mi", DefaultLimitCpu: "1", DefaultLimitMemory: "512Mi",
+	},
+	"large": {
+		RequestsCpu: "2", RequestsMemory: "4Gi", LimitsCpu: "4", LimitsMemory: "8Gi",
+		RequestsStorage: "50Gi", Pods: "20",
+		DefaultRequestCpu: "250m", DefaultRequestMemory: "512Mi", DefaultLimitCpu: "1", DefaultLimitMemory: "1Gi",
+	},
+}
+
+func (c *Config) GetQuotaProfiles() map[string]QuotaProfileConfig {
+	if len(c.Global.QuotaProfiles) == 0 {
+		return DefaultQuotaProfiles
+	}
+
+	return c.Global.QuotaProfiles
+}
+
+// Registries listed first take precedence when several match the same host
+// Project and profile lists are merged into containerRegistries, so they take precedence
+// over the single containerRegistry entry. That entry is for Docker Hub when it has no URI.
+func (c *Config) GetContainerRegistries() []ContainerRegistryConfig {
+	registries := append([]ContainerRegistryConfig{}, c.Global.ContainerRegistries...)
+	if c.Global.ContainerRegistry.Uri != "" || c.Global.ContainerRegistry.User != "" {
+		registries = append(registries, c.Global.ContainerRegistry)
+	}
+
+	return registries
+}
+
+func (c *Config) ApplyProfile(name, kubeContext string) (string, error) {
+	// Fall back to the profile matching the current kube context
+	if name == "" {
+		name = c.findProfileByContext(kubeContext)
+		if name == "" {
+			return "", nil
+		}
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return "", fmt.Errorf("config profile %s does not exist", name)
+	}
+
+	mergeString(&c.Global.ContainerRegistry.Uri, profile.ContainerRegistry.Uri)
+	mergeString(&c.Global.ContainerRegistry.User, profile.ContainerRegistry.User)
+	mergeString(&c.Global.ContainerRegistry.Pass, profile.ContainerRegistry.Pass)
+	c.Global.ContainerRegistries = append(append([]ContainerRegistryConfig{}, profile.ContainerRegistries...), c.Global.ContainerRegistries...)
+	mergeString(&c.Global.Database.Host, profile.Database.Host)
+	mergeString(&c.Global.Database.User, profile.Database.User)
+	mergeString(&c.Global.Database.Pass, profile.Database.Pass)
+	mergeString(&c.Global.IngressClass, profile.IngressClass)
+	mergeString(&c.Global.Issuer, profile.Issuer)
+	mergeString(&c.Global.StorageClass, profile.StorageClass)
+	mergeString(&c.Global.Hostname.BaseDomain, profile.Hostname.BaseDomain)
+	mergeString(&c.Global.Hostname.Template, profile.Hostname.Template)
+
+	return name, nil
+}
+
+func (c *Config) findProfileByContext(kubeContext string) string {
+	if _, ok := c.Profiles[kubeContext]; ok {
+		return kubeContext
+	}
+
+	names := []string{}
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, context := range c.Profiles[name].Contexts {
+			if context == kubeContext {
+				return name
+			}
+		}
+	}
+
+	return ""
+}
+
+func mergeString(target *string, value string) {
+	if value != "" {
+		*target = value
+	}
 }
 
 func GetConfig() *Config {
 	cfg, err := readConfig(getUserHomeDir() + CONFIG_PATH)
 
 	if err != nil {
+		if fileExists(getUserHomeDir() + CONFIG_PATH) {
+			fmt.Printf("Could not load app config: %s\n", err)
+			fmt.Println("Run kade config validate for details")
+		}
 		fmt.Print("Could not load app config, will ask for required information...\n\n")
 		return &Config{}
 	}
 
+	if info, err := os.Stat(getUserHomeDir() + CONFIG_PATH); err == nil && info.Mode().Perm()&0077 != 0 {
+		fmt.Println("⚠ Config file is readable by other users, consider running: chmod 600 ~/.config/kade/config.yml")
+	}
+
 	fmt.Print("Loaded app config from ~/.config/kade/config.yml\n\n")
 	return cfg
 }
@@ -57,16 +215,17 @@ func CreateConfig() {
 	fmt.Print("Creating config file...\n\n")
 
 	cfg := Config{
+		Version: CONFIG_VERSION,
 		Global: GlobalConfig{
 			ContainerRegistry: ContainerRegistryConfig{
-				Uri:  prompts.TextInput("Container registry URI?", "", "", false),
+				Uri:  prompts.TextInput("Container registry URI(leave blank for Docker Hub)?", "", "", false),
 				User: prompts.TextInput("Container registry user?", "", "", false),
-				Pass: prompts.PassWordInput("Container registry password?", "", "", false),
+				Pass: prompts.PassWordInput("Container registry password(or ${ENV} / cmd: reference)?", "", "", false),
 			},
 			Database: DatabaseConfig{
 				Host: prompts.TextInput("Database host?", "", "", false),
 				User: prompts.TextInput("Database user?", "", "", false),
-				Pass: prompts.PassWordInput("Database password?", "", "", false),
+				Pass: prompts.PassWordInput("Database password(or ${ENV} / cmd: reference)?", "", "", false),
 			},
 		},
 	}
@@ -86,8 +245,23 @@ func readConfig(filename string) (*Config, error) {
 		return nil, err
 	}
 
+	// Upgrade config files written by older versions before reading them
+	migrated, fromVersion, err := migrateConfig(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if fromVersion != CONFIG_VERSION {
+		err = writeMigratedConfig(filename, buf, migrated)
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Printf("Migrated config file from version %d to %d, backup saved to %s.bak\n", fromVersion, CONFIG_VERSION, filename)
+	}
+
 	var cfg Config
-	err = yaml.Unmarshal(buf, &cfg)
+	err = yaml.Unmarshal(migrated, &cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -96,22 +270,37 @@ func readConfig(filename string) (*Config, error) {
 }
 
 func writeConfig(filename string, cfg *Config) error {
-	dir := strings.ReplaceAll(filename, "/config.yml", "")
+	var document yaml.Node
+	if err := document.Encode(cfg); err != nil {
+		return err
+	}
+
+	buf, err := encodeDocument(&document)
+	if err != nil {
+		return err
+	}
+
+	return writeConfigFile(filename, buf)
+}
+
+func writeConfigFile(filename string, buf []byte) error {
+	dir := filepath.Dir(filename)
 
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		err := os.MkdirAll(dir, 0755)
+		err := os.MkdirAll(dir, 0700)
 
 		if err != nil {
 			return err
 		}
 	}
 
-	buf, err := yaml.Marshal(cfg)
+	// The config may contain secrets, so keep it readable by the current user only
+	err := os.WriteFile(filename, buf, 0600)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(filename, buf, 0644)
+	return os.Chmod(filename, 0600)
 }
 
 func fileExists(filename string) bool {
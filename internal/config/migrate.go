@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Migrations upgrade the config from the version at the same index + 1 to the
+// next version. Config files written before versioning are version 1. They work
+// on the yaml.v3 node tree so that comments and key order survive the rewrite.
+var migrations = []func(root *yaml.Node){
+	// 1 -> 2: the schema only gained optional fields, so the version is all that changes
+	func(root *yaml.Node) {},
+}
+
+func migrateConfig(buf []byte) ([]byte, int, error) {
+	document, err := parseDocument(buf)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, 0, fmt.Errorf("config must be a mapping of keys to values")
+	}
+
+	version := 1
+	versionNode := findNode(root, "version")
+	if versionNode != nil {
+		number, err := strconv.Atoi(versionNode.Value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("config version must be a number")
+		}
+		version = number
+	}
+
+	if version < 1 {
+		return nil, version, fmt.Errorf("config version %d is not valid, versions start at 1", version)
+	}
+
+	if version > CONFIG_VERSION {
+		return nil, version, fmt.Errorf(
+			"config version %d is newer than the supported version %d, please update kade", version, CONFIG_VERSION)
+	}
+
+	if version == CONFIG_VERSION {
+		return buf, version, nil
+	}
+
+	for v := version; v < CONFIG_VERSION; v++ {
+		migrations[v-1](root)
+	}
+
+	// New version keys are added as the first key in the file
+	if versionNode != nil {
+		versionNode.Value = strconv.Itoa(CONFIG_VERSION)
+	} else {
+		root.Content = append([]*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"},
+			{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(CONFIG_VERSION)},
+		}, root.Content...)
+	}
+
+	migrated, err := encodeDocument(document)
+	if err != nil {
+		return nil, version, err
+	}
+
+	return migrated, version, nil
+}
+
+// The original file is kept next to the migrated one in case something goes wrong
+func writeMigratedConfig(filename string, original, migrated []byte) error {
+	if err := os.WriteFile(filename+".bak", original, 0600); err != nil {
+		return err
+	}
+
+	return writeConfigFile(filename, migrated)
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	PROJECT_CONFIG_FILE       = ".kade.yml"
+	PROJECT_EXPOSURE_INGRESS  = "ingress"
+	PROJECT_EXPOSURE_GATEWAY  = "gateway"
+	PROJECT_EXPOSURE_INTERNAL = "internal"
+)
+
+type ProjectDatabaseConfig struct {
+	Host string `yaml:"host,omitempty"`
+	Name string `yaml:"name,omitempty"`
+	User string `yaml:"user,omitempty"`
+}
+
+type ProjectBootstrapConfig struct {
+	SiteTitle          string   `yaml:"siteTitle,omitempty"`
+	AdminUser          string   `yaml:"adminUser,omitempty"`
+	AdminEmail         string   `yaml:"adminEmail,omitempty"`
+	Locale             string   `yaml:"locale,omitempty"`
+	PermalinkStructure string   `yaml:"permalinkStructure,omitempty"`
+	Plugins            []string `yaml:"plugins,omitempty"`
+	Themes             []string `yaml:"themes,omitempty"`
+}
+
+// A project config is stored as .kade.yml in a website repository and declares
+// everything needed to deploy it, so only secrets have to be entered
+type ProjectConfig struct {
+	App                 string                    `yaml:"app"`
+	Namespace           string                    `yaml:"namespace"`
+	DeploymentName      string                    `yaml:"deploymentName,omitempty"`
+	RancherProject      string                    `yaml:"rancherProject,omitempty"`
+	QuotaProfile        string                    `yaml:"quotaProfile,omitempty"`
+	Image               string                    `yaml:"image"`
+	ContainerRegistry   ContainerRegistryConfig   `yaml:"containerRegistry,omitempty"`
+	ContainerRegistries []ContainerRegistryConfig `yaml:"containerRegistries,omitempty"`
+	ImagePullSecret     string                    `yaml:"imagePullSecret,omitempty"`
+	UploadsVolumeSize   string                    `yaml:"uploadsVolumeSize,omitempty"`
+	StorageClass        string                    `yaml:"storageClass,omitempty"`
+	AccessMode          string                    `yaml:"accessMode,omitempty"`
+	Exposure            string                    `yaml:"exposure,omitempty"`
+	Gateway             string                    `yaml:"gateway,omitempty"`
+	IngressClass        string                    `yaml:"ingressClass,omitempty"`
+	Hostname            HostnameConfig            `yaml:"hostname,omitempty"`
+	Aliases             []string                  `yaml:"aliases,omitempty"`
+	RedirectAliases     bool                      `yaml:"redirectAliases,omitempty"`
+	Tls                 bool                      `yaml:"tls,omitempty"`
+	TlsSecret           string                    `yaml:"tlsSecret,omitempty"`
+	Issuer              string                    `yaml:"issuer,omitempty"`
+	BasicAuthUser       string                    `yaml:"basicAuthUser,omitempty"`
+	AllowedSourceRanges []string                  `yaml:"allowedSourceRanges,omitempty"`
+	Database            ProjectDatabaseConfig     `yaml:"database,omitempty"`
+	WordPress           WordPressConfig           `yaml:"wordpress,omitempty"`
+	Debug               bool                      `yaml:"debug,omitempty"`
+	Hardened            *bool                     `yaml:"hardened,omitempty"`
+	NetworkPolicies     bool                      `yaml:"networkPolicies,omitempty"`
+	Bootstrap           *ProjectBootstrapConfig   `yaml:"bootstrap,omitempty"`
+}
+
+func ReadProjectConfig(filename string) (*ProjectConfig, error) {
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unknown fields are most likely typos, so report them with their position
+	var project ProjectConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(buf))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&project); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(filename), err)
+	}
+
+	if project.App == "" {
+		return nil, fmt.Errorf("%s: app is required", filepath.Base(filename))
+	}
+
+	if project.Namespace == "" {
+		return nil, fmt.Errorf("%s: namespace is required", filepath.Base(filename))
+	}
+
+	if project.Image == "" {
+		return nil, fmt.Errorf("%s: image is required", filepath.Base(filename))
+	}
+
+	// Registry passwords should only be committed as ${ENV} references
+	project.ContainerRegistry.Pass, err = resolveEnvSecret(project.ContainerRegistry.Pass)
+	if err != nil {
+		return nil, fmt.Errorf("%s: could not resolve containerRegistry.pass: %w", filepath.Base(filename), err)
+	}
+
+	for i := range project.ContainerRegistries {
+		project.ContainerRegistries[i].Pass, err = resolveEnvSecret(project.ContainerRegistries[i].Pass)
+		if err != nil {
+			return nil, fmt.Errorf("%s: could not resolve containerRegistries[%d].pass: %w", filepath.Base(filename), i, err)
+		}
+	}
+
+	switch project.Exposure {
+	case "", PROJECT_EXPOSURE_INGRESS, PROJECT_EXPOSURE_INTERNAL:
+	case PROJECT_EXPOSURE_GATEWAY:
+		if project.Gateway == "" {
+			return nil, fmt.Errorf("%s: gateway is required when exposure is gateway", filepath.Base(filename))
+		}
+	default:
+		return nil, fmt.Errorf("%s: exposure must be one of ingress, gateway or internal", filepath.Base(filename))
+	}
+
+	return &project, nil
+}
+
+// Project settings take precedence over the user config and the selected profile
+func (c *Config) ApplyProject(project *ProjectConfig) {
+	mergeString(&c.Global.ContainerRegistry.Uri, project.ContainerRegistry.Uri)
+	mergeString(&c.Global.ContainerRegistry.User, project.ContainerRegistry.User)
+	mergeString(&c.Global.ContainerRegistry.Pass, project.ContainerRegistry.Pass)
+	c.Global.ContainerRegistries = append(append([]ContainerRegistryConfig{}, project.ContainerRegistries...), c.Global.ContainerRegistries...)
+	mergeString(&c.Global.Database.Host, project.Database.Host)
+	mergeString(&c.Global.Database.User, project.Database.User)
+	mergeString(&c.Global.IngressClass, project.IngressClass)
+	mergeString(&c.Global.Issuer, project.Issuer)
+	mergeString(&c.Global.StorageClass, project.StorageClass)
+	mergeString(&c.Global.Hostname.BaseDomain, project.Hostname.BaseDomain)
+	mergeString(&c.Global.Hostname.Template, project.Hostname.Template)
+
+	wordpress := &c.Global.WordPress
+	wordpress.Env = mergeMap(wordpress.Env, project.WordPress.Env)
+	wordpress.Constants = mergeMap(wordpress.Constants, project.WordPress.Constants)
+	wordpress.PhpIni = mergeMap(wordpress.PhpIni, project.WordPress.PhpIni)
+	mergeString(&wordpress.ConfigExtra, project.WordPress.ConfigExtra)
+	mergeString(&wordpress.Resources.CpuRequest, project.WordPress.Resources.CpuRequest)
+	mergeString(&wordpress.Resources.MemoryRequest, project.WordPress.Resources.MemoryRequest)
+	mergeString(&wordpress.Resources.CpuLimit, project.WordPress.Resources.CpuLimit)
+	mergeString(&wordpress.Resources.MemoryLimit, project.WordPress.Resources.MemoryLimit)
+}
+
+func mergeMap(target, values map[string]string) map[string]string {
+	if len(values) == 0 {
+		return target
+	}
+
+	merged := map[string]string{}
+	for key, value := range target {
+		merged[key] = value
+	}
+
+	for key, value := range values {
+		merged[key] = value
+	}
+
+	return merged
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	CONFIG_VERSION = 2
+)
+
+func splitKey(key string) []string {
+	return strings.Split(key, ".")
+}
+
+// Returns the document node of a config file, empty files get an empty mapping
+func parseDocument(buf []byte) (*yaml.Node, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(buf, &document); err != nil {
+		return nil, err
+	}
+
+	if document.Kind == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+
+	return &document, nil
+}
+
+func encodeDocument(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(node); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Replaces the value at the path, keeping the comments of the node that is replaced
+func setNode(node *yaml.Node, path []string, value *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		*node = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", HeadComment: node.HeadComment, LineComment: node.LineComment}
+	}
+
+	for i := 1; i < len(node.Content); i += 2 {
+		if node.Content[i-1].Value != path[0] {
+			continue
+		}
+
+		if len(path) == 1 {
+			value.HeadComment = node.Content[i].HeadComment
+			value.LineComment = node.Content[i].LineComment
+			value.FootComment = node.Content[i].FootComment
+			node.Content[i] = value
+			return
+		}
+
+		setNode(node.Content[i], path[1:], value)
+		return
+	}
+
+	// Create missing keys along the path
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: path[0]}
+	if len(path) == 1 {
+		node.Content = append(node.Content, key, value)
+		return
+	}
+
+	child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	setNode(child, path[1:], value)
+	node.Content = append(node.Content, key, child)
+}
+
+// Returns the Go type stored at the path, following yaml tags and map values
+func getFieldType(t reflect.Type, path []string) (reflect.Type, bool) {
+	if len(path) == 0 {
+		return t, true
+	}
+
+	switch t.Kind() {
+	case reflect.Map:
+		return getFieldType(t.Elem(), path[1:])
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if name == path[0] {
+				return getFieldType(t.Field(i).Type, path[1:])
+			}
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+const (
+	SECRET_COMMAND_PREFIX = "cmd:"
+)
+
+var secretEnvReference = regexp.MustCompile(`^\$\{([A-Za-z_][A-Za-z0-9_]*)\}$`)
+
+// Only the merged global config is resolved, so credential helpers of other profiles never run
+func (c *Config) ResolveSecrets() error {
+	err := resolveSecretFields(map[string]*string{
+		"containerRegistry.pass": &c.Global.ContainerRegistry.Pass,
+		"database.pass":          &c.Global.Database.Pass,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return resolveRegistrySecrets("containerRegistries", c.Global.ContainerRegistries)
+}
+
+func resolveSecretFields(fields map[string]*string) error {
+	for field, value := range fields {
+		resolved, err := resolveSecret(*value)
+		if err != nil {
+			return fmt.Errorf("could not resolve %s: %w", field, err)
+		}
+
+		*value = resolved
+	}
+
+	return nil
+}
+
+func resolveRegistrySecrets(field string, registries []ContainerRegistryConfig) error {
+	for i := range registries {
+		resolved, err := resolveSecret(registries[i].Pass)
+		if err != nil {
+			return fmt.Errorf("could not resolve %s[%d].pass: %w", field, i, err)
+		}
+
+		registries[i].Pass = resolved
+	}
+
+	return nil
+}
+
+// Secrets can reference an environment variable with ${NAME}, or the output of
+// a credential helper with cmd:<command>, e.g. "cmd:pass show kade/db"
+func resolveSecret(value string) (string, error) {
+	if match := secretEnvReference.FindStringSubmatch(value); match != nil {
+		resolved, ok := os.LookupEnv(match[1])
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", match[1])
+		}
+
+		return resolved, nil
+	}
+
+	if strings.HasPrefix(value, SECRET_COMMAND_PREFIX) {
+		command := strings.TrimSpace(strings.TrimPrefix(value, SECRET_COMMAND_PREFIX))
+
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", command)
+		} else {
+			cmd = exec.Command("sh", "-c", command)
+		}
+
+		cmd.Stderr = os.Stderr
+		output, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("command %q failed: %w", command, err)
+		}
+
+		return strings.TrimRight(string(output), "\r\n"), nil
+	}
+
+	return value, nil
+}
+
+// Project files are committed to website repositories, so they can't run commands
+func resolveEnvSecret(value string) (string, error) {
+	if strings.HasPrefix(value, SECRET_COMMAND_PREFIX) {
+		return "", fmt.Errorf("cmd: references are not allowed in project files, use ${ENV} instead")
+	}
+
+	return resolveSecret(value)
+}
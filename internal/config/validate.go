@@ -0,0 +1,138 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+type ValidationError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Line == 0 {
+		return e.Message
+	}
+
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+func validateConfig(buf []byte) []ValidationError {
+	// yaml.v3 reports the position of syntax errors and unknown fields
+	var cfg Config
+	decoder := yaml.NewDecoder(bytes.NewReader(buf))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&cfg); err != nil && err != io.EOF {
+		return []ValidationError{{Message: err.Error()}}
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(buf, &root); err != nil {
+		return []ValidationError{{Message: err.Error()}}
+	}
+
+	if len(root.Content) == 0 {
+		return []ValidationError{}
+	}
+
+	document := root.Content[0]
+	errors := []ValidationError{}
+
+	if node := findNode(document, "version"); node != nil && cfg.Version > CONFIG_VERSION {
+		errors = append(errors, ValidationError{
+			Line:    node.Line,
+			Column:  node.Column,
+			Message: fmt.Sprintf("version %d is newer than the supported version %d", cfg.Version, CONFIG_VERSION),
+		})
+	}
+
+	if node := findNode(document, "version"); node != nil && cfg.Version < 1 {
+		errors = append(errors, ValidationError{
+			Line:    node.Line,
+			Column:  node.Column,
+			Message: fmt.Sprintf("version %d is not valid, versions start at 1", cfg.Version),
+		})
+	}
+
+	errors = append(errors, validateQuantities(findNode(document, "global", "wordpress", "resources"))...)
+
+	if profiles := findNode(document, "global", "quotaProfiles"); profiles != nil {
+		for i := 1; i < len(profiles.Content); i += 2 {
+			errors = append(errors, validateQuantities(profiles.Content[i])...)
+		}
+	}
+
+	// Only one profile can be selected for a kube context
+	names := []string{}
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, context := range cfg.Profiles[name].Contexts {
+			if other := cfg.findProfileByContext(context); other != name && other != "" {
+				node := findNode(document, "profiles", name, "contexts")
+				errors = append(errors, ValidationError{
+					Line:    node.Line,
+					Column:  node.Column,
+					Message: fmt.Sprintf("kube context %s is already matched by profile %s", context, other),
+				})
+			}
+		}
+	}
+
+	return errors
+}
+
+func validateQuantities(node *yaml.Node) []ValidationError {
+	errors := []ValidationError{}
+
+	if node == nil || node.Kind != yaml.MappingNode {
+		return errors
+	}
+
+	for i := 1; i < len(node.Content); i += 2 {
+		key, value := node.Content[i-1], node.Content[i]
+
+		if value.Kind != yaml.ScalarNode || value.Value == "" {
+			continue
+		}
+
+		if _, err := resource.ParseQuantity(value.Value); err != nil {
+			errors = append(errors, ValidationError{
+				Line:    value.Line,
+				Column:  value.Column,
+				Message: fmt.Sprintf("%s: %s is not a valid resource quantity", key.Value, value.Value),
+			})
+		}
+	}
+
+	return errors
+}
+
+func findNode(node *yaml.Node, path ...string) *yaml.Node {
+	if node == nil || len(path) == 0 {
+		return node
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 1; i < len(node.Content); i += 2 {
+		if node.Content[i-1].Value == path[0] {
+			return findNode(node.Content[i], path[1:]...)
+		}
+	}
+
+	return nil
+}
@@ -77,3 +77,23 @@ func PassWordInput(label, placeholder, initialValue string, required bool) strin
 
 	return value
 }
+
+func ValidatedTextInput(label, placeholder, initialValue string, validate func(value string) error) string {
+	input := textinput.New(label)
+	if placeholder != "" {
+		input.Placeholder = placeholder
+	}
+	if initialValue != "" {
+		input.InitialValue = initialValue
+	}
+
+	input.Validate = validate
+
+	value, err := input.RunPrompt()
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+
+	return value
+}
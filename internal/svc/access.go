@@ -0,0 +1,132 @@
+package svc
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/adde/kade/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	WP_PLACEHOLDER_BASIC_AUTH_USER    = "preview"
+	WP_PLACEHOLDER_SOURCE_RANGES      = "203.0.113.0/24, 198.51.100.10/32"
+	BASIC_AUTH_PASSWORD_LENGTH        = 16
+	BASIC_AUTH_REALM                  = "Authentication required"
+	K8S_BASIC_AUTH_SECRET_NAME        = "wp-basic-auth"
+	K8S_BASIC_AUTH_NGINX_KEY          = "auth"
+	K8S_BASIC_AUTH_TRAEFIK_KEY        = "users"
+	K8S_BASIC_AUTH_USER_KEY           = "user"
+	K8S_BASIC_AUTH_PASS_KEY           = "password"
+	K8S_TRAEFIK_BASIC_AUTH_MIDDLEWARE = "-basic-auth"
+	K8S_TRAEFIK_ALLOWLIST_MIDDLEWARE  = "-ip-allowlist"
+)
+
+func ValidateSourceRanges(value string) error {
+	for _, sourceRange := range utils.SplitList(value) {
+		if _, _, err := net.ParseCIDR(sourceRange); err != nil {
+			return fmt.Errorf("invalid CIDR %q", sourceRange)
+		}
+	}
+
+	return nil
+}
+
+// Basic auth and allowlists are configured through controller specific annotations and middlewares
+func (w WordPress) SupportsAccessControl() bool {
+	return w.Exposure == EXPOSURE_INGRESS &&
+		(w.IngressController == INGRESS_CONTROLLER_NGINX || w.IngressController == INGRESS_CONTROLLER_TRAEFIK)
+}
+
+func (w *WordPress) CreateBasicAuthSecret(successMessage, existsMessage string) *corev1.Secret {
+	if !w.BasicAuth || !w.SupportsAccessControl() {
+		return nil
+	}
+
+	w.BasicAuthPass = utils.GeneratePassword(BASIC_AUTH_PASSWORD_LENGTH)
+
+	// The same htpasswd entry is stored under the keys expected by nginx and Traefik
+	htpasswd := []byte(getHtpasswdEntry(w.BasicAuthUser, w.BasicAuthPass))
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      K8S_BASIC_AUTH_SECRET_NAME,
+			Namespace: w.Namespace,
+			Labels:    getManagedByLabels(),
+		},
+		Data: map[string][]byte{
+			K8S_BASIC_AUTH_NGINX_KEY:   htpasswd,
+			K8S_BASIC_AUTH_TRAEFIK_KEY: htpasswd,
+			K8S_BASIC_AUTH_USER_KEY:    []byte(w.BasicAuthUser),
+			K8S_BASIC_AUTH_PASS_KEY:    []byte(w.BasicAuthPass),
+		},
+		Type: corev1.SecretTypeOpaque,
+	}
+
+	createdSecret, err := w.Clientset.CoreV1().Secrets(secret.Namespace).Create(
+		context.Background(),
+		secret,
+		metav1.CreateOptions{},
+	)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			fmt.Print(existsMessage)
+
+			// Reuse the stored credentials since they are the ones in effect
+			existingSecret, err := w.Clientset.CoreV1().Secrets(secret.Namespace).Get(
+				context.Background(),
+				K8S_BASIC_AUTH_SECRET_NAME,
+				metav1.GetOptions{},
+			)
+
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			w.BasicAuthUser = string(existingSecret.Data[K8S_BASIC_AUTH_USER_KEY])
+			w.BasicAuthPass = string(existingSecret.Data[K8S_BASIC_AUTH_PASS_KEY])
+
+			return existingSecret
+		} else {
+			log.Fatal(err)
+		}
+	} else {
+		fmt.Printf(successMessage, createdSecret.Name)
+	}
+
+	return createdSecret
+}
+
+func (w WordPress) getAccessAnnotations() map[string]string {
+	annotations := map[string]string{}
+
+	if w.IngressController != INGRESS_CONTROLLER_NGINX {
+		return annotations
+	}
+
+	if w.BasicAuth {
+		annotations["nginx.ingress.kubernetes.io/auth-type"] = "basic"
+		annotations["nginx.ingress.kubernetes.io/auth-secret"] = K8S_BASIC_AUTH_SECRET_NAME
+		annotations["nginx.ingress.kubernetes.io/auth-realm"] = BASIC_AUTH_REALM
+	}
+
+	if len(w.AllowedSourceRanges) > 0 {
+		annotations["nginx.ingress.kubernetes.io/whitelist-source-range"] = strings.Join(w.AllowedSourceRanges, ",")
+	}
+
+	return annotations
+}
+
+// Both nginx and Traefik support the {SHA} htpasswd format
+func getHtpasswdEntry(user, pass string) string {
+	hash := sha1.Sum([]byte(pass))
+
+	return user + ":{SHA}" + base64.StdEncoding.EncodeToString(hash[:]) + "\n"
+}
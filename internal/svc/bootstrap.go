@@ -0,0 +1,225 @@
+package svc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/adde/kade/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	WP_PLACEHOLDER_SITE_TITLE   = "My project"
+	WP_PLACEHOLDER_ADMIN_USER   = "admin"
+	WP_PLACEHOLDER_ADMIN_EMAIL  = "admin@example.com"
+	WP_PLACEHOLDER_LOCALE       = "en_US"
+	WP_PLACEHOLDER_PERMALINKS   = "/%postname%/"
+	WP_PLACEHOLDER_PLUGINS      = "wordfence, wp-mail-smtp"
+	WP_PLACEHOLDER_THEMES       = "twentytwentyfour"
+	WP_CLI_PHAR_URL             = "https://raw.githubusercontent.com/wp-cli/builds/gh-pages/phar/wp-cli.phar"
+	WP_ADMIN_PASSWORD_LENGTH    = 24
+	K8S_ADMIN_SECRET_NAME       = "wp-admin"
+	K8S_ADMIN_SECRET_USER_KEY   = "user"
+	K8S_ADMIN_SECRET_EMAIL_KEY  = "email"
+	K8S_ADMIN_SECRET_PASS_KEY   = "password"
+	K8S_WORDPRESS_DOCUMENT_ROOT = "/var/www/html"
+	WP_ALREADY_INSTALLED_MARKER = "kade: WordPress is already installed"
+)
+
+func (w *WordPress) CreateAdminSecret(successMessage, existsMessage string) *corev1.Secret {
+	w.AdminPass = utils.GeneratePassword(WP_ADMIN_PASSWORD_LENGTH)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      K8S_ADMIN_SECRET_NAME,
+			Namespace: w.Namespace,
+			Labels:    getManagedByLabels(),
+		},
+		Data: map[string][]byte{
+			K8S_ADMIN_SECRET_USER_KEY:  []byte(w.AdminUser),
+			K8S_ADMIN_SECRET_EMAIL_KEY: []byte(w.AdminEmail),
+			K8S_ADMIN_SECRET_PASS_KEY:  []byte(w.AdminPass),
+		},
+		Type: corev1.SecretTypeOpaque,
+	}
+
+	createdSecret, err := w.Clientset.CoreV1().Secrets(secret.Namespace).Create(
+		context.Background(),
+		secret,
+		metav1.CreateOptions{},
+	)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			fmt.Print(existsMessage)
+
+			// Reuse the stored credentials so they match any previous installation
+			existingSecret, err := w.Clientset.CoreV1().Secrets(secret.Namespace).Get(
+				context.Background(),
+				K8S_ADMIN_SECRET_NAME,
+				metav1.GetOptions{},
+			)
+
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			w.AdminUser = string(existingSecret.Data[K8S_ADMIN_SECRET_USER_KEY])
+			w.AdminPass = string(existingSecret.Data[K8S_ADMIN_SECRET_PASS_KEY])
+
+			return existingSecret
+		} else {
+			log.Fatal(err)
+		}
+	} else {
+		fmt.Printf(successMessage, createdSecret.Name)
+	}
+
+	return createdSecret
+}
+
+// Returns true only when WordPress was installed by this run
+func (w WordPress) BootstrapSite(successMessage, alreadyInstalledMessage, failedMessage string) bool {
+	output, err := w.execInPod(w.getBootstrapScript())
+
+	if err != nil {
+		fmt.Printf(failedMessage, err)
+		if output != "" {
+			fmt.Println(output)
+		}
+		return false
+	}
+
+	if strings.Contains(output, WP_ALREADY_INSTALLED_MARKER) {
+		fmt.Print(alreadyInstalledMessage)
+		return false
+	}
+
+	fmt.Print(successMessage)
+
+	return true
+}
+
+func (w WordPress) getBootstrapScript() string {
+	commands := []string{
+		"set -e",
+		"WP=wp",
+		// Download wp-cli for images that do not ship with it
+		"if ! command -v wp >/dev/null 2>&1; then " +
+			"curl -sSLo /tmp/wp-cli.phar " + WP_CLI_PHAR_URL + "; " +
+			"WP='php /tmp/wp-cli.phar'; fi",
+		"WP=\"$WP --allow-root --path=" + K8S_WORDPRESS_DOCUMENT_ROOT + "\"",
+		"if $WP core is-installed; then echo " + shellQuote(WP_ALREADY_INSTALLED_MARKER) + "; else $WP core install" +
+			" --url=" + shellQuote(w.GetDeploymentUrl()) +
+			" --title=" + shellQuote(w.SiteTitle) +
+			" --admin_user=" + shellQuote(w.AdminUser) +
+			" --admin_password=" + shellQuote(w.AdminPass) +
+			" --admin_email=" + shellQuote(w.AdminEmail) +
+			" --skip-email; fi",
+	}
+
+	if w.Locale != "" && w.Locale != WP_PLACEHOLDER_LOCALE {
+		commands = append(commands, "$WP language core install "+shellQuote(w.Locale)+" --activate")
+	}
+
+	if w.PermalinkStructure != "" {
+		commands = append(commands, "$WP rewrite structure "+shellQuote(w.PermalinkStructure))
+	}
+
+	if len(w.Plugins) > 0 {
+		commands = append(commands, "$WP plugin install "+shellQuoteAll(w.Plugins)+" --activate")
+	}
+
+	// Only one theme can be active, so the first one in the list is activated
+	for i, theme := range w.Themes {
+		if i == 0 {
+			commands = append(commands, "$WP theme install "+shellQuote(theme)+" --activate")
+		} else {
+			commands = append(commands, "$WP theme install "+shellQuote(theme))
+		}
+	}
+
+	return strings.Join(commands, "\n")
+}
+
+func (w WordPress) execInPod(script string) (string, error) {
+	pod, err := w.getRunningPod()
+	if err != nil {
+		return "", err
+	}
+
+	request := w.Clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(w.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: w.DeploymentName,
+			Command:   []string{"sh", "-c", script},
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(w.RestConfig, "POST", request.URL())
+	if err != nil {
+		return "", err
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = executor.StreamWithContext(context.Background(), remotecommand.StreamOptions{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+
+	return strings.TrimSpace(stdout.String() + stderr.String()), err
+}
+
+func (w WordPress) getRunningPod() (*corev1.Pod, error) {
+	deployment, err := w.Clientset.AppsV1().Deployments(w.Namespace).Get(
+		context.Background(),
+		w.DeploymentName,
+		metav1.GetOptions{},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := w.Clientset.CoreV1().Pods(w.Namespace).List(
+		context.Background(),
+		metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(deployment.Spec.Selector.MatchLabels).String(),
+			FieldSelector: "status.phase=Running",
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("no running pods found for deployment %s", w.DeploymentName)
+	}
+
+	return &pods.Items[0], nil
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func shellQuoteAll(values []string) string {
+	quoted := []string{}
+	for _, value := range values {
+		quoted = append(quoted, shellQuote(value))
+	}
+
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,16 @@
+package svc
+
+import (
+	"log"
+
+	"k8s.io/client-go/dynamic"
+)
+
+func (w WordPress) getDynamicClient() dynamic.Interface {
+	client, err := dynamic.NewForConfig(w.RestConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return client
+}
@@ -0,0 +1,284 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	GATEWAY_LISTENER_HTTP        = "HTTP"
+	GATEWAY_LISTENER_HTTPS       = "HTTPS"
+	GATEWAY_ROUTES_FROM_ALL      = "All"
+	GATEWAY_ROUTES_FROM_SELECTOR = "Selector"
+)
+
+var K8S_GATEWAY_RESOURCE = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1",
+	Resource: "gateways",
+}
+
+var K8S_HTTPROUTE_RESOURCE = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1",
+	Resource: "httproutes",
+}
+
+func GetGateways(restConfig *rest.Config) []string {
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return []string{}
+	}
+
+	gateways, err := client.Resource(K8S_GATEWAY_RESOURCE).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return []string{}
+	}
+
+	names := []string{}
+	for _, gateway := range gateways.Items {
+		names = append(names, gateway.GetNamespace()+"/"+gateway.GetName())
+	}
+
+	return names
+}
+
+func (w WordPress) CreateHTTPRoute(successMessage, existsMessage, skipMessage string) *unstructured.Unstructured {
+	if w.Exposure != EXPOSURE_GATEWAY {
+		fmt.Print(skipMessage)
+		return nil
+	}
+
+	client := w.getDynamicClient()
+
+	sectionNames, err := w.getGatewayListeners(client)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The route is attached to the listener of every hostname
+	parentRefs := []interface{}{}
+	for _, sectionName := range sectionNames {
+		parentRefs = append(parentRefs, map[string]interface{}{
+			"name":        w.GatewayName,
+			"namespace":   w.GatewayNamespace,
+			"sectionName": sectionName,
+		})
+	}
+
+	route := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": K8S_HTTPROUTE_RESOURCE.GroupVersion().String(),
+			"kind":       "HTTPRoute",
+			"spec": map[string]interface{}{
+				"parentRefs": parentRefs,
+				"hostnames":  w.getRouteHostnames(),
+				"rules": []interface{}{
+					map[string]interface{}{
+						"backendRefs": []interface{}{
+							map[string]interface{}{
+								"name": w.DeploymentName,
+								"port": int64(80),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	route.SetName(w.DeploymentName)
+	route.SetNamespace(w.Namespace)
+	route.SetLabels(getManagedByLabels())
+
+	createdRoute, err := client.Resource(K8S_HTTPROUTE_RESOURCE).Namespace(w.Namespace).Create(
+		context.Background(),
+		route,
+		metav1.CreateOptions{},
+	)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			fmt.Print(existsMessage)
+		} else {
+			log.Fatal(err)
+		}
+	} else {
+		fmt.Printf(successMessage, createdRoute.GetName())
+	}
+
+	return createdRoute
+}
+
+// Checks that the gateway accepts routes from the namespace of the app
+func (w WordPress) ValidateGateway() error {
+	if w.Exposure != EXPOSURE_GATEWAY {
+		return nil
+	}
+
+	_, err := w.getGatewayListeners(w.getDynamicClient())
+	return err
+}
+
+// Returns the listener of every hostname. Routes are attached to HTTPS listeners when
+// TLS is wanted, TLS itself is terminated by the gateway listener matching the hostname
+func (w WordPress) getGatewayListeners(client dynamic.Interface) ([]string, error) {
+	gateway, err := client.Resource(K8S_GATEWAY_RESOURCE).Namespace(w.GatewayNamespace).Get(
+		context.Background(),
+		w.GatewayName,
+		metav1.GetOptions{},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	protocol := GATEWAY_LISTENER_HTTP
+	if w.IngressTls {
+		protocol = GATEWAY_LISTENER_HTTPS
+	}
+
+	namespaceLabels := w.getNamespaceLabels()
+	listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+
+	names := []string{}
+	for _, host := range w.GetHostnames() {
+		name, matched := "", false
+
+		for _, item := range listeners {
+			listener, ok := item.(map[string]interface{})
+			if !ok || listener["protocol"] != protocol || !listenerMatchesHostname(listener, host) {
+				continue
+			}
+
+			matched = true
+			if w.listenerAllowsNamespace(listener, namespaceLabels) {
+				name, _ = listener["name"].(string)
+				break
+			}
+		}
+
+		if name == "" && matched {
+			return nil, fmt.Errorf("gateway %s/%s doesn't allow routes from namespace %s, check allowedRoutes of its listeners",
+				w.GatewayNamespace, w.GatewayName, w.Namespace)
+		}
+
+		if name == "" {
+			return nil, fmt.Errorf("gateway %s/%s has no %s listener for %s", w.GatewayNamespace, w.GatewayName, protocol, host)
+		}
+
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+func listenerMatchesHostname(listener map[string]interface{}, host string) bool {
+	hostname, _ := listener["hostname"].(string)
+
+	return hostname == "" || hostname == host ||
+		(strings.HasPrefix(hostname, "*.") && strings.HasSuffix(host, hostname[1:]))
+}
+
+// Listeners only accept routes from their own namespace unless allowedRoutes says otherwise
+func (w WordPress) listenerAllowsNamespace(listener map[string]interface{}, namespaceLabels map[string]string) bool {
+	from, _, _ := unstructured.NestedString(listener, "allowedRoutes", "namespaces", "from")
+
+	switch from {
+	case GATEWAY_ROUTES_FROM_ALL:
+		return true
+	case GATEWAY_ROUTES_FROM_SELECTOR:
+		selectorObject, _, _ := unstructured.NestedMap(listener, "allowedRoutes", "namespaces", "selector")
+
+		var labelSelector metav1.LabelSelector
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(selectorObject, &labelSelector); err != nil {
+			return false
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(&labelSelector)
+		if err != nil {
+			return false
+		}
+
+		return selector.Matches(labels.Set(namespaceLabels))
+	default:
+		return w.Namespace == w.GatewayNamespace
+	}
+}
+
+// New namespaces get the labels kade creates them with
+func (w WordPress) getNamespaceLabels() map[string]string {
+	namespace, err := w.Clientset.CoreV1().Namespaces().Get(context.Background(), w.Namespace, metav1.GetOptions{})
+	if err == nil {
+		return namespace.Labels
+	}
+
+	namespaceLabels := getManagedByLabels()
+	namespaceLabels[corev1.LabelMetadataName] = w.Namespace
+	if w.RancherProject != nil {
+		namespaceLabels[K8S_RANCHER_PROJECT_ID_FIELD] = w.RancherProject.Name
+	}
+
+	return namespaceLabels
+}
+
+func (w WordPress) IsHTTPRouteReady() bool {
+	route, err := w.getDynamicClient().Resource(K8S_HTTPROUTE_RESOURCE).Namespace(w.Namespace).Get(
+		context.Background(),
+		w.DeploymentName,
+		metav1.GetOptions{},
+	)
+
+	if err != nil {
+		return false
+	}
+
+	parents, _, _ := unstructured.NestedSlice(route.Object, "status", "parents")
+	for _, item := range parents {
+		parent, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		conditions, _, _ := unstructured.NestedSlice(parent, "conditions")
+		if hasTrueCondition(conditions, "Accepted") && hasTrueCondition(conditions, "ResolvedRefs") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasTrueCondition(conditions []interface{}, conditionType string) bool {
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if ok && condition["type"] == conditionType && condition["status"] == "True" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w WordPress) getRouteHostnames() []interface{} {
+	hostnames := []interface{}{}
+	for _, hostname := range w.GetHostnames() {
+		hostnames = append(hostnames, hostname)
+	}
+
+	return hostnames
+}
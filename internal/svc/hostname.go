@@ -0,0 +1,122 @@
+package svc
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	HOSTNAME_DEFAULT_TEMPLATE          = "{{.Namespace}}.{{.BaseDomain}}"
+	HOSTNAME_DEFAULT_WILDCARD_TEMPLATE = "{{.Namespace}}-{{.DeploymentName}}.{{.IP}}.{{.BaseDomain}}"
+	K8S_APP_NAME_LABEL                 = "app.kubernetes.io/name"
+	K8S_APP_INSTANCE_LABEL             = "app.kubernetes.io/instance"
+)
+
+var WILDCARD_DNS_DOMAINS = []string{
+	"nip.io",
+	"sslip.io",
+}
+
+type HostnameData struct {
+	Namespace      string
+	DeploymentName string
+	BaseDomain     string
+	IP             string
+	IPDashed       string
+}
+
+func IsWildcardDnsDomain(baseDomain string) bool {
+	for _, domain := range WILDCARD_DNS_DOMAINS {
+		if baseDomain == domain {
+			return true
+		}
+	}
+
+	return false
+}
+
+func RenderHostname(hostnameTemplate string, data HostnameData) string {
+	if hostnameTemplate == "" {
+		if data.BaseDomain == "" {
+			return ""
+		}
+
+		hostnameTemplate = HOSTNAME_DEFAULT_TEMPLATE
+		if IsWildcardDnsDomain(data.BaseDomain) {
+			hostnameTemplate = HOSTNAME_DEFAULT_WILDCARD_TEMPLATE
+		}
+	}
+
+	tmpl, err := template.New("hostname").Option("missingkey=error").Parse(hostnameTemplate)
+	if err != nil {
+		return ""
+	}
+
+	var hostname bytes.Buffer
+	if err := tmpl.Execute(&hostname, data); err != nil {
+		return ""
+	}
+
+	return strings.ToLower(hostname.String())
+}
+
+// Wildcard DNS hostnames have to point at the address traffic enters the cluster through
+func (w WordPress) GetEntryPointIP() string {
+	hosts := []string{}
+
+	switch w.Exposure {
+	case EXPOSURE_GATEWAY:
+		hosts = w.getGatewayAddresses()
+	case EXPOSURE_INGRESS:
+		if service := w.getIngressControllerService(); service != nil {
+			for _, lbIngress := range service.Status.LoadBalancer.Ingress {
+				hosts = append(hosts, lbIngress.IP, lbIngress.Hostname)
+			}
+		}
+	}
+
+	if addresses := resolveAddresses(hosts); len(addresses) > 0 {
+		return addresses[0]
+	}
+
+	return ""
+}
+
+// Controller charts label the ingress class and the controller service with the same
+// instance, services are only matched by the controller name when that label is missing
+func (w WordPress) getIngressControllerService() *corev1.Service {
+	services, err := w.Clientset.CoreV1().Services("").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+
+	instance := ""
+	if w.IngressClass != "" {
+		ingressClass, err := w.Clientset.NetworkingV1().IngressClasses().Get(context.Background(), w.IngressClass, metav1.GetOptions{})
+		if err == nil {
+			instance = ingressClass.Labels[K8S_APP_INSTANCE_LABEL]
+		}
+	}
+
+	for _, service := range services.Items {
+		if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			continue
+		}
+
+		if instance != "" && service.Labels[K8S_APP_INSTANCE_LABEL] == instance {
+			return &service
+		}
+
+		name := service.Labels[K8S_APP_NAME_LABEL] + " " + service.Name
+		if instance == "" && w.IngressController != "" && strings.Contains(name, w.IngressController) {
+			return &service
+		}
+	}
+
+	return nil
+}
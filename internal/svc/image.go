@@ -0,0 +1,457 @@
+package svc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adde/kade/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	DOCKER_HUB_API_HOST = "registry-1.docker.io"
+	IMAGE_DEFAULT_TAG   = "latest"
+	IMAGE_TAG_LIMIT     = 20
+	IMAGE_TAG_PAGE_SIZE = 1000
+	IMAGE_TAG_MAX_PAGES = 20
+)
+
+var ErrImageNotFound = errors.New("image not found")
+
+var authChallengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+var tagChunk = regexp.MustCompile(`\d+|\D+`)
+var versionTag = regexp.MustCompile(`^v?\d`)
+var nextPageLink = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+
+var IMAGE_MANIFEST_MEDIA_TYPES = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+type ImageReference struct {
+	Name       string
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+type imagePlatform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant"`
+}
+
+type imageManifest struct {
+	Manifests []struct {
+		Platform imagePlatform `json:"platform"`
+	} `json:"manifests"`
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+}
+
+type registryClient struct {
+	host          string
+	repository    string
+	credentials   *RegistryCredentials
+	authorization string
+	client        *http.Client
+}
+
+func ParseImageReference(image string) ImageReference {
+	ref := ImageReference{Registry: GetImageRegistry(image)}
+
+	name := image
+	if host, rest, found := strings.Cut(image, "/"); found && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		ref.Name = host + "/"
+		name = rest
+	}
+
+	name, ref.Digest, _ = strings.Cut(name, "@")
+
+	// A colon after the last slash separates the tag
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.Tag = name[:i], name[i+1:]
+	}
+
+	ref.Name += name
+	ref.Repository = name
+	if ref.Registry == DOCKER_HUB_REGISTRY && !strings.Contains(name, "/") {
+		ref.Repository = "library/" + name
+	}
+
+	return ref
+}
+
+func (r ImageReference) GetReference() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+
+	return utils.ValueOrDefault(r.Tag, IMAGE_DEFAULT_TAG)
+}
+
+func (r ImageReference) WithTag(tag string) string {
+	return r.Name + ":" + tag
+}
+
+// Returns the platforms the image is built for, e.g. linux/amd64
+func (w WordPress) VerifyImage() ([]string, error) {
+	ref := ParseImageReference(w.ContainerImage)
+	client := w.getRegistryClient(ref)
+
+	var manifest imageManifest
+	if err := client.getJson("/manifests/"+ref.GetReference(), IMAGE_MANIFEST_MEDIA_TYPES, &manifest); err != nil {
+		return nil, err
+	}
+
+	platforms := []string{}
+	for _, entry := range manifest.Manifests {
+		// Attestation manifests are listed with an unknown platform
+		if entry.Platform.OS == "unknown" || entry.Platform.OS == "" {
+			continue
+		}
+		platforms = append(platforms, entry.Platform.OS+"/"+entry.Platform.Architecture)
+	}
+
+	// Single platform images only describe their platform in the config blob
+	if len(manifest.Manifests) == 0 && manifest.Config.Digest != "" {
+		var platform imagePlatform
+		if err := client.getJson("/blobs/"+manifest.Config.Digest, []string{}, &platform); err != nil {
+			return nil, err
+		}
+
+		if platform.OS != "" {
+			platforms = append(platforms, platform.OS+"/"+platform.Architecture)
+		}
+	}
+
+	return platforms, nil
+}
+
+// Registries don't expose when a tag was pushed, so tags are sorted by version instead.
+// Tags that aren't versions are left out, except for the default tag which is listed first.
+func (w WordPress) GetImageTags() ([]string, error) {
+	ref := ParseImageReference(w.ContainerImage)
+	client := w.getRegistryClient(ref)
+
+	tags := []string{}
+	hasDefaultTag := false
+
+	path := "/tags/list?n=" + strconv.Itoa(IMAGE_TAG_PAGE_SIZE)
+	for page := 0; path != "" && page < IMAGE_TAG_MAX_PAGES; page++ {
+		var response struct {
+			Tags []string `json:"tags"`
+		}
+
+		next, err := client.getJsonPage(path, &response)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tag := range response.Tags {
+			if tag == IMAGE_DEFAULT_TAG {
+				hasDefaultTag = true
+			} else if versionTag.MatchString(tag) {
+				tags = append(tags, tag)
+			}
+		}
+
+		path = next
+	}
+
+	// v1.2 and 1.2 are the same version
+	sort.SliceStable(tags, func(i, j int) bool {
+		return compareTags(strings.TrimPrefix(tags[i], "v"), strings.TrimPrefix(tags[j], "v")) > 0
+	})
+
+	if hasDefaultTag {
+		tags = append([]string{IMAGE_DEFAULT_TAG}, tags...)
+	}
+
+	if len(tags) > IMAGE_TAG_LIMIT {
+		tags = tags[:IMAGE_TAG_LIMIT]
+	}
+
+	return tags, nil
+}
+
+// Returns the platforms of cluster nodes that the image can't run on
+func (w WordPress) GetUnsupportedPlatforms(platforms []string) []string {
+	// Nothing can be said about images that don't describe their platforms
+	if len(platforms) == 0 {
+		return []string{}
+	}
+
+	nodes, err := w.Clientset.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return []string{}
+	}
+
+	unsupported := []string{}
+	for _, node := range nodes.Items {
+		platform := node.Status.NodeInfo.OperatingSystem + "/" + node.Status.NodeInfo.Architecture
+		if !slices.Contains(platforms, platform) && !slices.Contains(unsupported, platform) {
+			unsupported = append(unsupported, platform)
+		}
+	}
+
+	return unsupported
+}
+
+func (w WordPress) getRegistryClient(ref ImageReference) *registryClient {
+	host := ref.Registry
+	if host == DOCKER_HUB_REGISTRY {
+		host = DOCKER_HUB_API_HOST
+	}
+
+	return &registryClient{
+		host:        host,
+		repository:  ref.Repository,
+		credentials: w.getImageRegistryCredentials(ref.Registry),
+		client:      &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (w WordPress) getImageRegistryCredentials(registry string) *RegistryCredentials {
+	for _, credentials := range w.RegistryCredentials {
+		if NormalizeRegistry(credentials.Registry) == registry {
+			return &credentials
+		}
+	}
+
+	if w.ImagePullSecret == "" {
+		return nil
+	}
+
+	// Use the same credentials as the kubelet when an existing pull secret is referenced
+	secret, err := w.Clientset.CoreV1().Secrets(w.Namespace).Get(context.Background(), w.ImagePullSecret, metav1.GetOptions{})
+	if err != nil || secret.Type != corev1.SecretTypeDockerConfigJson {
+		return nil
+	}
+
+	var dockerConfig DockerConfig
+	if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &dockerConfig); err != nil {
+		return nil
+	}
+
+	for key, auth := range dockerConfig.Auths {
+		if NormalizeRegistry(key) != registry {
+			continue
+		}
+
+		username, password := auth.Username, auth.Password
+		if decoded, err := base64.StdEncoding.DecodeString(auth.Auth); err == nil && auth.Auth != "" {
+			username, password, _ = strings.Cut(string(decoded), ":")
+		}
+
+		return &RegistryCredentials{Registry: key, Username: username, Password: password}
+	}
+
+	return nil
+}
+
+func (c *registryClient) getJson(path string, accept []string, target interface{}) error {
+	response, err := c.fetch(path, accept)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return json.NewDecoder(response.Body).Decode(target)
+}
+
+// Returns the path of the next page from the Link header of paginated responses
+func (c *registryClient) getJsonPage(path string, target interface{}) (string, error) {
+	response, err := c.fetch(path, []string{})
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if err := json.NewDecoder(response.Body).Decode(target); err != nil {
+		return "", err
+	}
+
+	match := nextPageLink.FindStringSubmatch(response.Header.Get("Link"))
+	if match == nil {
+		return "", nil
+	}
+
+	next, err := url.Parse(match[1])
+	if err != nil {
+		return "", nil
+	}
+
+	// Links are relative to the registry host, paths are relative to the repository
+	prefix := "/v2/" + c.repository
+	if !strings.HasPrefix(next.Path, prefix) {
+		return "", nil
+	}
+
+	return strings.TrimPrefix(next.Path, prefix) + "?" + next.RawQuery, nil
+}
+
+// Returns a successful response, the caller has to close its body
+func (c *registryClient) fetch(path string, accept []string) (*http.Response, error) {
+	response, err := c.get(path, accept)
+	if err != nil {
+		return nil, err
+	}
+
+	// Authenticate with the scheme the registry asks for and try again
+	if response.StatusCode == http.StatusUnauthorized && c.authorization == "" {
+		response.Body.Close()
+
+		if err := c.authenticate(response.Header.Get("WWW-Authenticate")); err != nil {
+			return nil, err
+		}
+
+		response, err = c.get(path, accept)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if response.StatusCode == http.StatusOK {
+		return response, nil
+	}
+
+	response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusNotFound:
+		return nil, ErrImageNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("access to %s/%s was denied", c.host, c.repository)
+	default:
+		return nil, fmt.Errorf("registry %s responded with %s", c.host, response.Status)
+	}
+}
+
+func (c *registryClient) get(path string, accept []string) (*http.Response, error) {
+	scheme := "https"
+	if strings.HasPrefix(c.host, "localhost") || strings.HasPrefix(c.host, "127.0.0.1") {
+		scheme = "http"
+	}
+
+	request, err := http.NewRequest(http.MethodGet, scheme+"://"+c.host+"/v2/"+c.repository+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(accept) > 0 {
+		request.Header.Set("Accept", strings.Join(accept, ", "))
+	}
+
+	if c.authorization != "" {
+		request.Header.Set("Authorization", c.authorization)
+	}
+
+	return c.client.Do(request)
+}
+
+func (c *registryClient) authenticate(challenge string) error {
+	scheme, _, _ := strings.Cut(challenge, " ")
+
+	if strings.EqualFold(scheme, "Basic") {
+		if c.credentials == nil {
+			return fmt.Errorf("registry %s requires credentials", c.host)
+		}
+
+		c.authorization = "Basic " + base64.StdEncoding.EncodeToString(
+			[]byte(c.credentials.Username+":"+c.credentials.Password))
+
+		return nil
+	}
+
+	if !strings.EqualFold(scheme, "Bearer") {
+		return fmt.Errorf("registry %s uses an unsupported authentication scheme %q", c.host, scheme)
+	}
+
+	params := map[string]string{}
+	for _, match := range authChallengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+
+	query := url.Values{}
+	query.Set("scope", "repository:"+c.repository+":pull")
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+
+	request, err := http.NewRequest(http.MethodGet, params["realm"]+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	// Anonymous tokens are enough for public images
+	if c.credentials != nil {
+		request.SetBasicAuth(c.credentials.Username, c.credentials.Password)
+	}
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not authenticate with registry %s: %s", c.host, response.Status)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return err
+	}
+
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+
+	c.authorization = "Bearer " + token.Token
+
+	return nil
+}
+
+// Compares numbers in tags by value, so that 6.10 is newer than 6.9
+func compareTags(a, b string) int {
+	chunksA, chunksB := tagChunk.FindAllString(a, -1), tagChunk.FindAllString(b, -1)
+
+	for i := 0; i < len(chunksA) && i < len(chunksB); i++ {
+		numberA, errA := strconv.Atoi(chunksA[i])
+		numberB, errB := strconv.Atoi(chunksB[i])
+
+		if errA == nil && errB == nil {
+			if numberA != numberB {
+				return numberA - numberB
+			}
+			continue
+		}
+
+		if chunksA[i] != chunksB[i] {
+			return strings.Compare(chunksA[i], chunksB[i])
+		}
+	}
+
+	return len(chunksA) - len(chunksB)
+}
@@ -0,0 +1,317 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	INGRESS_CONTROLLER_NGINX             = "nginx"
+	INGRESS_CONTROLLER_TRAEFIK           = "traefik"
+	INGRESS_BODY_SIZE                    = "1G"
+	INGRESS_BODY_SIZE_BYTES              = 1 << 30
+	INGRESS_CONNECT_TIMEOUT              = "30"
+	INGRESS_READ_TIMEOUT                 = "600"
+	INGRESS_SEND_TIMEOUT                 = "600"
+	INGRESS_NOINDEX_HEADER               = "X-Robots-Tag"
+	INGRESS_NOINDEX_VALUE                = "noindex"
+	K8S_DEFAULT_INGRESS_CLASS_ANNOTATION = "ingressclass.kubernetes.io/is-default-class"
+	K8S_DEFAULT_INGRESS_CLASS_SUFFIX     = " (default)"
+	K8S_NGINX_CONTROLLER                 = "k8s.io/ingress-nginx"
+	K8S_TRAEFIK_CONTROLLER               = "traefik.io/ingress-controller"
+	K8S_TRAEFIK_MIDDLEWARES_ANNOTATION   = "traefik.ingress.kubernetes.io/router.middlewares"
+	K8S_TRAEFIK_HEADERS_MIDDLEWARE       = "-headers"
+	K8S_TRAEFIK_BUFFERING_MIDDLEWARE     = "-buffering"
+	K8S_TRAEFIK_REDIRECT_MIDDLEWARE      = "-redirect"
+	K8S_REDIRECT_INGRESS_SUFFIX          = "-redirect"
+	WP_PLACEHOLDER_ALIASES               = "www.myproject.example.com"
+)
+
+var K8S_TRAEFIK_MIDDLEWARE_RESOURCE = schema.GroupVersionResource{
+	Group:    "traefik.io",
+	Version:  "v1alpha1",
+	Resource: "middlewares",
+}
+
+func GetIngressClasses(clientset *kubernetes.Clientset) []string {
+	ingressClasses, err := clientset.NetworkingV1().IngressClasses().List(
+		context.Background(),
+		metav1.ListOptions{},
+	)
+
+	if err != nil {
+		return []string{}
+	}
+
+	// List the default ingress class first
+	names := []string{}
+	for _, ingressClass := range ingressClasses.Items {
+		if ingressClass.Annotations[K8S_DEFAULT_INGRESS_CLASS_ANNOTATION] == "true" {
+			names = append([]string{ingressClass.Name + K8S_DEFAULT_INGRESS_CLASS_SUFFIX}, names...)
+		} else {
+			names = append(names, ingressClass.Name)
+		}
+	}
+
+	return names
+}
+
+func GetIngressController(clientset *kubernetes.Clientset, ingressClassName string) string {
+	// Keep the previous behaviour for clusters without ingress classes
+	if ingressClassName == "" {
+		return INGRESS_CONTROLLER_NGINX
+	}
+
+	ingressClass, err := clientset.NetworkingV1().IngressClasses().Get(
+		context.Background(),
+		ingressClassName,
+		metav1.GetOptions{},
+	)
+
+	if err != nil {
+		return ""
+	}
+
+	switch ingressClass.Spec.Controller {
+	case K8S_NGINX_CONTROLLER:
+		return INGRESS_CONTROLLER_NGINX
+	case K8S_TRAEFIK_CONTROLLER:
+		return INGRESS_CONTROLLER_TRAEFIK
+	}
+
+	return ""
+}
+
+func (w WordPress) getIngressAnnotations() map[string]string {
+	switch w.IngressController {
+	case INGRESS_CONTROLLER_NGINX:
+		return map[string]string{
+			"nginx.ingress.kubernetes.io/proxy-body-size":       INGRESS_BODY_SIZE,
+			"nginx.ingress.kubernetes.io/proxy-connect-timeout": INGRESS_CONNECT_TIMEOUT,
+			"nginx.ingress.kubernetes.io/proxy-read-timeout":    INGRESS_READ_TIMEOUT,
+			"nginx.ingress.kubernetes.io/proxy-send-timeout":    INGRESS_SEND_TIMEOUT,
+			"nginx.ingress.kubernetes.io/configuration-snippet": fmt.Sprintf(
+				"more_set_headers \"%s: %s\";\n", INGRESS_NOINDEX_HEADER, INGRESS_NOINDEX_VALUE),
+		}
+	case INGRESS_CONTROLLER_TRAEFIK:
+		// Timeouts are configured on the Traefik entrypoints and can't be set per router
+		return map[string]string{
+			K8S_TRAEFIK_MIDDLEWARES_ANNOTATION: strings.Join(w.getTraefikMiddlewareRefs(), ","),
+		}
+	}
+
+	return map[string]string{}
+}
+
+func (w WordPress) getTraefikMiddlewareRefs() []string {
+	refs := []string{}
+	for _, middleware := range w.getTraefikMiddlewares() {
+		refs = append(refs, w.Namespace+"-"+middleware.GetName()+"@kubernetescrd")
+	}
+
+	return refs
+}
+
+func (w WordPress) getTraefikMiddlewares() []*unstructured.Unstructured {
+	middlewares := []*unstructured.Unstructured{
+		w.newTraefikMiddleware(w.DeploymentName+K8S_TRAEFIK_HEADERS_MIDDLEWARE, map[string]interface{}{
+			"headers": map[string]interface{}{
+				"customResponseHeaders": map[string]interface{}{
+					INGRESS_NOINDEX_HEADER: INGRESS_NOINDEX_VALUE,
+				},
+			},
+		}),
+		w.newTraefikMiddleware(w.DeploymentName+K8S_TRAEFIK_BUFFERING_MIDDLEWARE, map[string]interface{}{
+			"buffering": map[string]interface{}{
+				"maxRequestBodyBytes": int64(INGRESS_BODY_SIZE_BYTES),
+			},
+		}),
+	}
+
+	if w.BasicAuth {
+		middlewares = append(middlewares, w.newTraefikMiddleware(w.DeploymentName+K8S_TRAEFIK_BASIC_AUTH_MIDDLEWARE, map[string]interface{}{
+			"basicAuth": map[string]interface{}{
+				"secret": K8S_BASIC_AUTH_SECRET_NAME,
+				"realm":  BASIC_AUTH_REALM,
+			},
+		}))
+	}
+
+	// ipWhiteList is deprecated in Traefik v3 but is the only variant that works with v2 as well
+	if len(w.AllowedSourceRanges) > 0 {
+		sourceRanges := []interface{}{}
+		for _, sourceRange := range w.AllowedSourceRanges {
+			sourceRanges = append(sourceRanges, sourceRange)
+		}
+
+		middlewares = append(middlewares, w.newTraefikMiddleware(w.DeploymentName+K8S_TRAEFIK_ALLOWLIST_MIDDLEWARE, map[string]interface{}{
+			"ipWhiteList": map[string]interface{}{
+				"sourceRange": sourceRanges,
+			},
+		}))
+	}
+
+	return middlewares
+}
+
+func (w WordPress) newTraefikMiddleware(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	middleware := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": K8S_TRAEFIK_MIDDLEWARE_RESOURCE.GroupVersion().String(),
+			"kind":       "Middleware",
+			"spec":       spec,
+		},
+	}
+
+	middleware.SetName(name)
+	middleware.SetNamespace(w.Namespace)
+	middleware.SetLabels(getManagedByLabels())
+
+	return middleware
+}
+
+func (w WordPress) CreateTraefikMiddlewares(successMessage, existsMessage string) {
+	if w.Exposure != EXPOSURE_INGRESS || w.IngressController != INGRESS_CONTROLLER_TRAEFIK {
+		return
+	}
+
+	client := w.getDynamicClient()
+
+	for _, middleware := range append(w.getTraefikMiddlewares(), w.getTraefikRedirectMiddlewares()...) {
+		createdMiddleware, err := client.Resource(K8S_TRAEFIK_MIDDLEWARE_RESOURCE).Namespace(w.Namespace).Create(
+			context.Background(),
+			middleware,
+			metav1.CreateOptions{},
+		)
+
+		if err != nil {
+			if strings.Contains(err.Error(), "already exists") {
+				fmt.Printf(existsMessage, middleware.GetName())
+			} else {
+				log.Fatal(err)
+			}
+		} else {
+			fmt.Printf(successMessage, createdMiddleware.GetName())
+		}
+	}
+}
+
+func (w WordPress) GetHostnames() []string {
+	return append([]string{w.Hostname}, w.Aliases...)
+}
+
+func (w WordPress) getIngressRule(host string) networkingv1.IngressRule {
+	pathType := networkingv1.PathTypeImplementationSpecific
+
+	return networkingv1.IngressRule{
+		Host: host,
+		IngressRuleValue: networkingv1.IngressRuleValue{
+			HTTP: &networkingv1.HTTPIngressRuleValue{
+				Paths: []networkingv1.HTTPIngressPath{
+					{
+						PathType: &pathType,
+						Backend: networkingv1.IngressBackend{
+							Service: &networkingv1.IngressServiceBackend{
+								Name: w.DeploymentName,
+								Port: networkingv1.ServiceBackendPort{
+									Number: 80,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (w WordPress) getRedirectAnnotations() map[string]string {
+	switch w.IngressController {
+	case INGRESS_CONTROLLER_NGINX:
+		return map[string]string{
+			"nginx.ingress.kubernetes.io/permanent-redirect": w.GetDeploymentUrl() + "$request_uri",
+		}
+	case INGRESS_CONTROLLER_TRAEFIK:
+		return map[string]string{
+			K8S_TRAEFIK_MIDDLEWARES_ANNOTATION: w.Namespace + "-" + w.DeploymentName + K8S_TRAEFIK_REDIRECT_MIDDLEWARE + "@kubernetescrd",
+		}
+	}
+
+	return map[string]string{}
+}
+
+func (w WordPress) getTraefikRedirectMiddlewares() []*unstructured.Unstructured {
+	if !w.RedirectAliases || len(w.Aliases) == 0 {
+		return []*unstructured.Unstructured{}
+	}
+
+	return []*unstructured.Unstructured{
+		w.newTraefikMiddleware(w.DeploymentName+K8S_TRAEFIK_REDIRECT_MIDDLEWARE, map[string]interface{}{
+			"redirectRegex": map[string]interface{}{
+				"regex":       "^https?://[^/]+(.*)",
+				"replacement": w.GetDeploymentUrl() + "${1}",
+				"permanent":   true,
+			},
+		}),
+	}
+}
+
+func (w WordPress) CreateRedirectIngress(successMessage, existsMessage string) *networkingv1.Ingress {
+	if w.Exposure != EXPOSURE_INGRESS || !w.RedirectAliases || len(w.Aliases) == 0 {
+		return nil
+	}
+
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        w.DeploymentName + K8S_REDIRECT_INGRESS_SUFFIX,
+			Namespace:   w.Namespace,
+			Labels:      getManagedByLabels(),
+			Annotations: w.getRedirectAnnotations(),
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{},
+		},
+	}
+
+	for _, host := range w.Aliases {
+		ingress.Spec.Rules = append(ingress.Spec.Rules, w.getIngressRule(host))
+	}
+
+	if w.IngressClass != "" {
+		ingress.Spec.IngressClassName = &w.IngressClass
+	}
+
+	// Reuse the certificate from the main ingress, which is issued for all hostnames
+	if w.IngressTls {
+		ingress.Spec.TLS = append(ingress.Spec.TLS, networkingv1.IngressTLS{
+			Hosts:      w.Aliases,
+			SecretName: w.getTlsSecretName(),
+		})
+	}
+
+	createdIngress, err := w.Clientset.NetworkingV1().Ingresses(ingress.Namespace).Create(
+		context.Background(),
+		ingress,
+		metav1.CreateOptions{},
+	)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			fmt.Print(existsMessage)
+		} else {
+			log.Fatal(err)
+		}
+	} else {
+		fmt.Printf(successMessage, createdIngress.Name)
+	}
+
+	return createdIngress
+}
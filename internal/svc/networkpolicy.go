@@ -0,0 +1,244 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	DATABASE_DEFAULT_PORT               = 3306
+	K8S_NAMESPACE_NAME_LABEL            = "kubernetes.io/metadata.name"
+	K8S_DENY_ALL_POLICY_NAME            = "kade-default-deny"
+	K8S_ALLOW_INGRESS_POLICY_NAME       = "kade-allow-ingress-controller"
+	K8S_ALLOW_DNS_POLICY_NAME           = "kade-allow-dns"
+	K8S_ALLOW_DATABASE_POLICY_NAME      = "kade-allow-database"
+	K8S_ALLOW_INTERNET_POLICY_NAME      = "kade-allow-internet"
+	WP_PLACEHOLDER_CONTROLLER_NAMESPACE = "ingress-nginx"
+)
+
+var PRIVATE_NETWORKS = []string{
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+}
+
+func GetIngressControllerNamespace(clientset *kubernetes.Clientset, ingressController string) string {
+	services, err := clientset.CoreV1().Services("").List(context.Background(), metav1.ListOptions{})
+	if err != nil || ingressController == "" {
+		return ""
+	}
+
+	for _, service := range services.Items {
+		name := service.Labels[K8S_APP_NAME_LABEL] + " " + service.Name
+		if strings.Contains(name, ingressController) && service.Namespace != "default" {
+			return service.Namespace
+		}
+	}
+
+	return ""
+}
+
+func (w WordPress) CreateNetworkPolicies(successMessage, existsMessage string) {
+	if !w.NetworkPolicies {
+		return
+	}
+
+	for _, policy := range w.getNetworkPolicies() {
+		createdPolicy, err := w.Clientset.NetworkingV1().NetworkPolicies(w.Namespace).Create(
+			context.Background(),
+			policy,
+			metav1.CreateOptions{},
+		)
+
+		if err != nil {
+			if strings.Contains(err.Error(), "already exists") {
+				fmt.Printf(existsMessage, policy.Name)
+			} else {
+				log.Fatal(err)
+			}
+		} else {
+			fmt.Printf(successMessage, createdPolicy.Name)
+		}
+	}
+}
+
+func (w WordPress) getNetworkPolicies() []*networkingv1.NetworkPolicy {
+	policies := []*networkingv1.NetworkPolicy{
+		w.newNetworkPolicy(K8S_DENY_ALL_POLICY_NAME, nil, nil),
+		w.newNetworkPolicy(K8S_ALLOW_DNS_POLICY_NAME, nil, []networkingv1.NetworkPolicyEgressRule{
+			{
+				// DNS may run in any namespace depending on the distribution
+				To: []networkingv1.NetworkPolicyPeer{
+					{NamespaceSelector: &metav1.LabelSelector{}},
+				},
+				Ports: []networkingv1.NetworkPolicyPort{
+					newPolicyPort(corev1.ProtocolUDP, 53),
+					newPolicyPort(corev1.ProtocolTCP, 53),
+				},
+			},
+		}),
+		w.newNetworkPolicy(K8S_ALLOW_DATABASE_POLICY_NAME, nil, []networkingv1.NetworkPolicyEgressRule{
+			w.getDatabaseEgressRule(),
+		}),
+		w.newNetworkPolicy(K8S_ALLOW_INTERNET_POLICY_NAME, nil, []networkingv1.NetworkPolicyEgressRule{
+			{
+				To: []networkingv1.NetworkPolicyPeer{
+					{
+						IPBlock: &networkingv1.IPBlock{
+							CIDR:   "0.0.0.0/0",
+							Except: PRIVATE_NETWORKS,
+						},
+					},
+				},
+			},
+		}),
+	}
+
+	// Internal environments are only reached through port-forward, which isn't affected by policies
+	if w.Exposure != EXPOSURE_INTERNAL && w.IngressControllerNamespace != "" {
+		policies = append(policies, w.newNetworkPolicy(K8S_ALLOW_INGRESS_POLICY_NAME, []networkingv1.NetworkPolicyIngressRule{
+			{
+				From: []networkingv1.NetworkPolicyPeer{
+					{
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{
+								K8S_NAMESPACE_NAME_LABEL: w.IngressControllerNamespace,
+							},
+						},
+					},
+				},
+			},
+		}, nil))
+	}
+
+	return policies
+}
+
+func (w WordPress) newNetworkPolicy(name string, ingress []networkingv1.NetworkPolicyIngressRule, egress []networkingv1.NetworkPolicyEgressRule) *networkingv1.NetworkPolicy {
+	policyTypes := []networkingv1.PolicyType{}
+	if ingress != nil {
+		policyTypes = append(policyTypes, networkingv1.PolicyTypeIngress)
+	}
+	if egress != nil {
+		policyTypes = append(policyTypes, networkingv1.PolicyTypeEgress)
+	}
+
+	// A policy without rules denies all traffic in both directions
+	if ingress == nil && egress == nil {
+		policyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}
+	}
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: w.Namespace,
+			Labels:    getManagedByLabels(),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: policyTypes,
+			Ingress:     ingress,
+			Egress:      egress,
+		},
+	}
+}
+
+func (w WordPress) getDatabaseEgressRule() networkingv1.NetworkPolicyEgressRule {
+	host, port := parseDatabaseHost(w.DatabaseHost)
+
+	rule := networkingv1.NetworkPolicyEgressRule{
+		To: []networkingv1.NetworkPolicyPeer{},
+		Ports: []networkingv1.NetworkPolicyPort{
+			newPolicyPort(corev1.ProtocolTCP, port),
+		},
+	}
+
+	// In-cluster services are matched by namespace, external hosts by IP. Services can be
+	// referenced as <service>, <service>.<namespace> or <service>.<namespace>.svc..., names
+	// like db.internal are only services when a namespace with that name exists
+	parts := strings.Split(host, ".")
+	isService := len(parts) == 1 ||
+		(len(parts) == 2 && NamespaceExists(w.Clientset, parts[1])) ||
+		(len(parts) >= 3 && parts[2] == "svc")
+
+	if net.ParseIP(host) == nil && isService {
+		namespace := w.Namespace
+		if len(parts) > 1 {
+			namespace = parts[1]
+		}
+
+		rule.To = append(rule.To, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					K8S_NAMESPACE_NAME_LABEL: namespace,
+				},
+			},
+		})
+
+		return rule
+	}
+
+	addresses := []string{host}
+	if net.ParseIP(host) == nil {
+		resolved, err := net.LookupHost(host)
+		if err == nil {
+			addresses = resolved
+		}
+	}
+
+	for _, address := range addresses {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			continue
+		}
+
+		cidr := address + "/32"
+		if ip.To4() == nil {
+			cidr = address + "/128"
+		}
+
+		rule.To = append(rule.To, networkingv1.NetworkPolicyPeer{
+			IPBlock: &networkingv1.IPBlock{CIDR: cidr},
+		})
+	}
+
+	// A rule without peers would allow traffic to any destination
+	if len(rule.To) == 0 {
+		log.Fatalf("database host %s could not be resolved, network policies can't be created", host)
+	}
+
+	return rule
+}
+
+func parseDatabaseHost(databaseHost string) (string, int) {
+	host, portValue, err := net.SplitHostPort(databaseHost)
+	if err != nil {
+		return databaseHost, DATABASE_DEFAULT_PORT
+	}
+
+	port, err := strconv.Atoi(portValue)
+	if err != nil {
+		return host, DATABASE_DEFAULT_PORT
+	}
+
+	return host, port
+}
+
+func newPolicyPort(protocol corev1.Protocol, port int) networkingv1.NetworkPolicyPort {
+	portValue := intstr.FromInt(port)
+
+	return networkingv1.NetworkPolicyPort{
+		Protocol: &protocol,
+		Port:     &portValue,
+	}
+}
@@ -0,0 +1,114 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/adde/kade/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	WP_DEFAULT_PHP_MEMORY_LIMIT = "256M"
+	WP_DEFAULT_UPLOAD_MAX_SIZE  = "64M"
+	K8S_PHP_CONFIGMAP_NAME      = "wp-php-config"
+	K8S_PHP_CONFIGMAP_KEY       = "kade.ini"
+	K8S_PHP_CONFIG_DIR          = "/usr/local/etc/php/conf.d"
+	K8S_WORDPRESS_CONFIG_EXTRA  = "WORDPRESS_CONFIG_EXTRA"
+	PHP_INI_MEMORY_LIMIT        = "memory_limit"
+	PHP_INI_UPLOAD_MAX_FILESIZE = "upload_max_filesize"
+	PHP_INI_POST_MAX_SIZE       = "post_max_size"
+	WP_CONSTANT_MEMORY_LIMIT    = "WP_MEMORY_LIMIT"
+	WP_CONSTANT_DEBUG           = "WP_DEBUG"
+	WP_CONSTANT_DEBUG_LOG       = "WP_DEBUG_LOG"
+	WP_CONSTANT_DEBUG_DISPLAY   = "WP_DEBUG_DISPLAY"
+)
+
+var phpLiteralValue = regexp.MustCompile(`^(true|false|null|-?[0-9]+(\.[0-9]+)?)$`)
+
+func (w WordPress) CreatePhpConfigMap(successMessage, existsMessage, skipMessage string) *corev1.ConfigMap {
+	if len(w.PhpIni) == 0 {
+		fmt.Print(skipMessage)
+		return nil
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      K8S_PHP_CONFIGMAP_NAME,
+			Namespace: w.Namespace,
+			Labels:    getManagedByLabels(),
+		},
+		Data: map[string]string{
+			K8S_PHP_CONFIGMAP_KEY: w.getPhpIni(),
+		},
+	}
+
+	createdConfigMap, err := w.Clientset.CoreV1().ConfigMaps(configMap.Namespace).Create(
+		context.Background(),
+		configMap,
+		metav1.CreateOptions{},
+	)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			fmt.Print(existsMessage)
+		} else {
+			log.Fatal(err)
+		}
+	} else {
+		fmt.Printf(successMessage, createdConfigMap.Name)
+	}
+
+	return createdConfigMap
+}
+
+func (w WordPress) getPhpIni() string {
+	lines := []string{}
+	for _, key := range utils.SortedKeys(w.PhpIni) {
+		lines = append(lines, key+" = "+w.PhpIni[key])
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (w WordPress) getConfigExtra() string {
+	constants := map[string]string{}
+
+	if w.Debug {
+		constants[WP_CONSTANT_DEBUG] = "true"
+		constants[WP_CONSTANT_DEBUG_LOG] = "true"
+		constants[WP_CONSTANT_DEBUG_DISPLAY] = "false"
+	}
+
+	// Let WordPress use the same memory limit as PHP unless explicitly set
+	if memoryLimit, ok := w.PhpIni[PHP_INI_MEMORY_LIMIT]; ok {
+		constants[WP_CONSTANT_MEMORY_LIMIT] = memoryLimit
+	}
+
+	for key, value := range w.Constants {
+		constants[key] = value
+	}
+
+	lines := []string{}
+	for _, key := range utils.SortedKeys(constants) {
+		lines = append(lines, fmt.Sprintf("define('%s', %s);", key, formatPhpValue(constants[key])))
+	}
+
+	if w.ConfigExtra != "" {
+		lines = append(lines, w.ConfigExtra)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func formatPhpValue(value string) string {
+	if phpLiteralValue.MatchString(strings.ToLower(value)) {
+		return value
+	}
+
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(value, `\`, `\\`), "'", `\'`) + "'"
+}
@@ -0,0 +1,106 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+const (
+	K8S_PORT_FORWARD_LOCAL_PORT = 8080
+)
+
+func GetManagedServices(clientset *kubernetes.Clientset, namespace string) ([]corev1.Service, error) {
+	services, err := clientset.CoreV1().Services(namespace).List(
+		context.Background(),
+		metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(getManagedByLabels()).String(),
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return services.Items, nil
+}
+
+func PortForward(restConfig *rest.Config, clientset *kubernetes.Clientset, service corev1.Service, localPort int, readyMessage string) error {
+	pods, err := clientset.CoreV1().Pods(service.Namespace).List(
+		context.Background(),
+		metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(service.Spec.Selector).String(),
+			FieldSelector: "status.phase=Running",
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if len(pods.Items) == 0 {
+		return fmt.Errorf("no running pods found for service %s", service.Name)
+	}
+
+	targetPort := service.Spec.Ports[0].TargetPort.IntValue()
+	if targetPort == 0 {
+		targetPort = int(service.Spec.Ports[0].Port)
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(restConfig)
+	if err != nil {
+		return err
+	}
+
+	url := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(service.Namespace).
+		Name(pods.Items[0].Name).
+		SubResource("portforward").
+		URL()
+
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+	stopChan := make(chan struct{}, 1)
+	readyChan := make(chan struct{})
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	go func() {
+		<-signals
+		close(stopChan)
+	}()
+
+	forwarder, err := portforward.New(
+		dialer,
+		[]string{fmt.Sprintf("%d:%d", localPort, targetPort)},
+		stopChan,
+		readyChan,
+		io.Discard,
+		os.Stderr,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-readyChan
+		fmt.Printf(readyMessage, fmt.Sprintf("http://localhost:%d", localPort))
+	}()
+
+	return forwarder.ForwardPorts()
+}
@@ -0,0 +1,184 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	QUOTA_NO_PROFILE             = "None"
+	K8S_QUOTA_NAME               = "kade-quota"
+	K8S_LIMIT_RANGE_NAME         = "kade-limits"
+	K8S_QUOTA_PROFILE_ANNOTATION = "kade/quota-profile"
+)
+
+type QuotaProfile struct {
+	Name                 string
+	RequestsCpu          string
+	RequestsMemory       string
+	LimitsCpu            string
+	LimitsMemory         string
+	RequestsStorage      string
+	Pods                 string
+	DefaultRequestCpu    string
+	DefaultRequestMemory string
+	DefaultLimitCpu      string
+	DefaultLimitMemory   string
+}
+
+func NamespaceExists(clientset *kubernetes.Clientset, namespace string) bool {
+	_, err := clientset.CoreV1().Namespaces().Get(context.Background(), namespace, metav1.GetOptions{})
+
+	return err == nil
+}
+
+func ValidateQuotaProfile(profile QuotaProfile) error {
+	for _, value := range []string{
+		profile.RequestsCpu, profile.RequestsMemory, profile.LimitsCpu, profile.LimitsMemory,
+		profile.RequestsStorage, profile.Pods, profile.DefaultRequestCpu, profile.DefaultRequestMemory,
+		profile.DefaultLimitCpu, profile.DefaultLimitMemory,
+	} {
+		if err := ValidateQuantity(value); err != nil {
+			return fmt.Errorf("quota profile %s: %s", profile.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (w WordPress) CreateResourceQuota(successMessage, existsMessage string) *corev1.ResourceQuota {
+	if w.QuotaProfile == nil {
+		return nil
+	}
+
+	hard := corev1.ResourceList{}
+	addQuantity(hard, corev1.ResourceRequestsCPU, w.QuotaProfile.RequestsCpu)
+	addQuantity(hard, corev1.ResourceRequestsMemory, w.QuotaProfile.RequestsMemory)
+	addQuantity(hard, corev1.ResourceLimitsCPU, w.QuotaProfile.LimitsCpu)
+	addQuantity(hard, corev1.ResourceLimitsMemory, w.QuotaProfile.LimitsMemory)
+	addQuantity(hard, corev1.ResourceRequestsStorage, w.QuotaProfile.RequestsStorage)
+	addQuantity(hard, corev1.ResourcePods, w.QuotaProfile.Pods)
+
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      K8S_QUOTA_NAME,
+			Namespace: w.Namespace,
+			Labels:    getManagedByLabels(),
+			Annotations: map[string]string{
+				K8S_QUOTA_PROFILE_ANNOTATION: w.QuotaProfile.Name,
+			},
+		},
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: hard,
+		},
+	}
+
+	createdQuota, err := w.Clientset.CoreV1().ResourceQuotas(quota.Namespace).Create(
+		context.Background(),
+		quota,
+		metav1.CreateOptions{},
+	)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			fmt.Print(existsMessage)
+		} else {
+			log.Fatal(err)
+		}
+	} else {
+		fmt.Printf(successMessage, createdQuota.Name, w.QuotaProfile.Name)
+	}
+
+	return createdQuota
+}
+
+func (w WordPress) CreateLimitRange(successMessage, existsMessage string) *corev1.LimitRange {
+	if w.QuotaProfile == nil {
+		return nil
+	}
+
+	// Containers without resources get defaults, otherwise they are rejected by the quota
+	defaultLimits := corev1.ResourceList{}
+	addQuantity(defaultLimits, corev1.ResourceCPU, w.QuotaProfile.DefaultLimitCpu)
+	addQuantity(defaultLimits, corev1.ResourceMemory, w.QuotaProfile.DefaultLimitMemory)
+
+	defaultRequests := corev1.ResourceList{}
+	addQuantity(defaultRequests, corev1.ResourceCPU, w.QuotaProfile.DefaultRequestCpu)
+	addQuantity(defaultRequests, corev1.ResourceMemory, w.QuotaProfile.DefaultRequestMemory)
+
+	limitRange := &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      K8S_LIMIT_RANGE_NAME,
+			Namespace: w.Namespace,
+			Labels:    getManagedByLabels(),
+		},
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{
+				{
+					Type:           corev1.LimitTypeContainer,
+					Default:        defaultLimits,
+					DefaultRequest: defaultRequests,
+				},
+			},
+		},
+	}
+
+	createdLimitRange, err := w.Clientset.CoreV1().LimitRanges(limitRange.Namespace).Create(
+		context.Background(),
+		limitRange,
+		metav1.CreateOptions{},
+	)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			fmt.Print(existsMessage)
+		} else {
+			log.Fatal(err)
+		}
+	} else {
+		fmt.Printf(successMessage, createdLimitRange.Name)
+	}
+
+	return createdLimitRange
+}
+
+func (w WordPress) GetQuotaUsage() []string {
+	quota, err := w.Clientset.CoreV1().ResourceQuotas(w.Namespace).Get(
+		context.Background(),
+		K8S_QUOTA_NAME,
+		metav1.GetOptions{},
+	)
+
+	if err != nil {
+		return []string{}
+	}
+
+	names := []string{}
+	for name := range quota.Status.Hard {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	usage := []string{}
+	for _, name := range names {
+		used := quota.Status.Used[corev1.ResourceName(name)]
+		hard := quota.Status.Hard[corev1.ResourceName(name)]
+		usage = append(usage, fmt.Sprintf("%s: %s / %s", name, used.String(), hard.String()))
+	}
+
+	return usage
+}
+
+func addQuantity(list corev1.ResourceList, name corev1.ResourceName, value string) {
+	if value != "" {
+		list[name] = resource.MustParse(value)
+	}
+}
@@ -0,0 +1,87 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	RANCHER_NO_PROJECT           = "None"
+	RANCHER_LOCAL_CLUSTER        = "local"
+	K8S_RANCHER_PROJECT_ID_FIELD = "field.cattle.io/projectId"
+)
+
+var rancherClusterUrl = regexp.MustCompile(`/k8s/clusters/([^/]+)`)
+
+var K8S_RANCHER_PROJECT_RESOURCE = schema.GroupVersionResource{
+	Group:    "management.cattle.io",
+	Version:  "v3",
+	Resource: "projects",
+}
+
+type RancherProject struct {
+	ClusterId   string
+	Name        string
+	DisplayName string
+}
+
+func (p RancherProject) GetProjectId() string {
+	return p.ClusterId + ":" + p.Name
+}
+
+func (p RancherProject) String() string {
+	return p.DisplayName + " (" + p.GetProjectId() + ")"
+}
+
+// Projects are management objects that only exist on the Rancher local cluster, in a
+// namespace named after the cluster they belong to. Clusters reached through the Rancher
+// proxy are queried through the proxy of the local cluster instead.
+func GetRancherProjects(restConfig *rest.Config) ([]RancherProject, error) {
+	projects := []RancherProject{}
+
+	clusterId := ""
+	managementConfig := restConfig
+	if match := rancherClusterUrl.FindStringSubmatch(restConfig.Host); match != nil {
+		clusterId = match[1]
+		managementConfig = rest.CopyConfig(restConfig)
+		managementConfig.Host = rancherClusterUrl.ReplaceAllString(restConfig.Host, "/k8s/clusters/"+RANCHER_LOCAL_CLUSTER)
+	}
+
+	client, err := dynamic.NewForConfig(managementConfig)
+	if err != nil {
+		return projects, err
+	}
+
+	list, err := client.Resource(K8S_RANCHER_PROJECT_RESOURCE).Namespace(clusterId).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		// Clusters that aren't managed by Rancher don't have projects at all
+		if clusterId == "" {
+			return projects, nil
+		}
+
+		return projects, fmt.Errorf("could not list Rancher projects of cluster %s: %w", clusterId, err)
+	}
+
+	for _, item := range list.Items {
+		displayName, _, _ := unstructured.NestedString(item.Object, "spec", "displayName")
+		projects = append(projects, RancherProject{
+			ClusterId:   item.GetNamespace(),
+			Name:        item.GetName(),
+			DisplayName: displayName,
+		})
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].String() < projects[j].String()
+	})
+
+	return projects, nil
+}
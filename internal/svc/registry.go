@@ -0,0 +1,220 @@
+package svc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/homedir"
+)
+
+const (
+	DOCKER_HUB_REGISTRY           = "docker.io"
+	DOCKER_HUB_AUTH_KEY           = "https://index.docker.io/v1/"
+	DOCKER_CREDENTIAL_HELPER      = "docker-credential-"
+	REGISTRY_AUTH_APP_CONFIG      = "Credentials from kade config"
+	REGISTRY_AUTH_DOCKER_CONFIG   = "Credentials from docker config"
+	REGISTRY_AUTH_EXISTING_SECRET = "Existing image pull secret"
+	REGISTRY_AUTH_MANUAL          = "Enter registry credentials"
+	REGISTRY_AUTH_NONE            = "None (public image)"
+)
+
+type RegistryCredentials struct {
+	Registry string
+	Username string
+	Password string
+}
+
+type dockerConfigFile struct {
+	Auths map[string]struct {
+		Auth          string `json:"auth"`
+		Username      string `json:"username"`
+		Password      string `json:"password"`
+		IdentityToken string `json:"identitytoken"`
+	} `json:"auths"`
+	CredHelpers map[string]string `json:"credHelpers"`
+	CredsStore  string            `json:"credsStore"`
+}
+
+// Returns the registry host of an image reference, images without one are pulled from Docker Hub
+func GetImageRegistry(image string) string {
+	host, _, found := strings.Cut(image, "/")
+	if !found {
+		return DOCKER_HUB_REGISTRY
+	}
+
+	if strings.ContainsAny(host, ".:") || host == "localhost" {
+		return NormalizeRegistry(host)
+	}
+
+	return DOCKER_HUB_REGISTRY
+}
+
+// Registries are configured as URIs or hosts, e.g. https://index.docker.io/v1/ or ghcr.io
+func NormalizeRegistry(registry string) string {
+	registry = strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+	registry, _, _ = strings.Cut(registry, "/")
+
+	switch registry {
+	case "index.docker.io", "registry-1.docker.io":
+		return DOCKER_HUB_REGISTRY
+	}
+
+	return registry
+}
+
+// Looks up credentials for a registry the same way the docker cli does, checking
+// credential helpers first and the auths stored in the config file last
+func GetDockerCredentials(registry string) (RegistryCredentials, bool) {
+	dockerConfig, err := readDockerConfig()
+	if err != nil {
+		return RegistryCredentials{}, false
+	}
+
+	for _, key := range getRegistryAuthKeys(registry) {
+		if helper, ok := dockerConfig.CredHelpers[key]; ok {
+			if credentials, ok := getHelperCredentials(helper, key); ok {
+				return credentials, true
+			}
+		}
+	}
+
+	if dockerConfig.CredsStore != "" {
+		for _, key := range getRegistryAuthKeys(registry) {
+			if credentials, ok := getHelperCredentials(dockerConfig.CredsStore, key); ok {
+				return credentials, true
+			}
+		}
+	}
+
+	for _, key := range getRegistryAuthKeys(registry) {
+		auth, ok := dockerConfig.Auths[key]
+		if !ok {
+			continue
+		}
+
+		// Identity tokens can only be exchanged by the docker cli itself
+		if auth.IdentityToken != "" {
+			continue
+		}
+
+		username, password := auth.Username, auth.Password
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				continue
+			}
+			username, password, _ = strings.Cut(string(decoded), ":")
+		}
+
+		if username != "" && password != "" {
+			return RegistryCredentials{Registry: key, Username: username, Password: password}, true
+		}
+	}
+
+	return RegistryCredentials{}, false
+}
+
+func GetRegistryAuthKey(registry string) string {
+	return getRegistryAuthKeys(registry)[0]
+}
+
+func GetImagePullSecrets(clientset *kubernetes.Clientset, namespace string) []string {
+	secrets, err := clientset.CoreV1().Secrets(namespace).List(
+		context.Background(),
+		metav1.ListOptions{},
+	)
+
+	if err != nil {
+		return []string{}
+	}
+
+	names := []string{}
+	for _, secret := range secrets.Items {
+		if secret.Type == corev1.SecretTypeDockerConfigJson || secret.Type == corev1.SecretTypeDockercfg {
+			names = append(names, secret.Name)
+		}
+	}
+
+	return names
+}
+
+func (w WordPress) hasRegistryCredentials() bool {
+	return len(w.RegistryCredentials) > 0
+}
+
+func (w WordPress) getImagePullSecretName() string {
+	if w.ImagePullSecret != "" {
+		return w.ImagePullSecret
+	}
+
+	if w.hasRegistryCredentials() {
+		return K8S_REGISTRY_SECRET_NAME
+	}
+
+	return ""
+}
+
+func readDockerConfig() (*dockerConfigFile, error) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		dir = filepath.Join(homedir.HomeDir(), ".docker")
+	}
+
+	buf, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var dockerConfig dockerConfigFile
+	if err := json.Unmarshal(buf, &dockerConfig); err != nil {
+		return nil, err
+	}
+
+	return &dockerConfig, nil
+}
+
+// Docker Hub credentials are stored under several different keys depending on the client
+func getRegistryAuthKeys(registry string) []string {
+	if registry == DOCKER_HUB_REGISTRY {
+		return []string{DOCKER_HUB_AUTH_KEY, DOCKER_HUB_REGISTRY, "index.docker.io", "registry-1.docker.io"}
+	}
+
+	return []string{registry, "https://" + registry}
+}
+
+func getHelperCredentials(helper, registry string) (RegistryCredentials, bool) {
+	cmd := exec.Command(DOCKER_CREDENTIAL_HELPER+helper, "get")
+	cmd.Stdin = strings.NewReader(registry)
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		return RegistryCredentials{}, false
+	}
+
+	var response struct {
+		Username string
+		Secret   string
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil || response.Secret == "" {
+		return RegistryCredentials{}, false
+	}
+
+	// Helpers return identity tokens with this placeholder user, they can't be used for basic auth
+	if response.Username == "<token>" {
+		return RegistryCredentials{}, false
+	}
+
+	return RegistryCredentials{Registry: registry, Username: response.Username, Password: response.Secret}, true
+}
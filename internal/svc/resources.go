@@ -0,0 +1,124 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	WP_DEFAULT_CPU_REQUEST    = "100m"
+	WP_DEFAULT_MEMORY_REQUEST = "256Mi"
+	WP_DEFAULT_CPU_LIMIT      = "1"
+	WP_DEFAULT_MEMORY_LIMIT   = "512Mi"
+	WP_READINESS_PROBE_PATH   = "/wp-login.php"
+)
+
+func ValidateQuantity(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	_, err := resource.ParseQuantity(value)
+	if err != nil {
+		return fmt.Errorf("invalid quantity %q", value)
+	}
+
+	return nil
+}
+
+func (w WordPress) ValidateResources() error {
+	for _, quantity := range []string{w.CpuRequest, w.MemoryRequest, w.CpuLimit, w.MemoryLimit} {
+		if err := ValidateQuantity(quantity); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w WordPress) getResources() corev1.ResourceRequirements {
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{},
+		Limits:   corev1.ResourceList{},
+	}
+
+	if w.CpuRequest != "" {
+		resources.Requests[corev1.ResourceCPU] = resource.MustParse(w.CpuRequest)
+	}
+	if w.MemoryRequest != "" {
+		resources.Requests[corev1.ResourceMemory] = resource.MustParse(w.MemoryRequest)
+	}
+	if w.CpuLimit != "" {
+		resources.Limits[corev1.ResourceCPU] = resource.MustParse(w.CpuLimit)
+	}
+	if w.MemoryLimit != "" {
+		resources.Limits[corev1.ResourceMemory] = resource.MustParse(w.MemoryLimit)
+	}
+
+	return resources
+}
+
+func (w WordPress) getReadinessProbe() *corev1.Probe {
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: WP_READINESS_PROBE_PATH,
+				Port: intstr.FromInt(80),
+			},
+		},
+		InitialDelaySeconds: 10,
+		PeriodSeconds:       10,
+		TimeoutSeconds:      5,
+		FailureThreshold:    3,
+	}
+}
+
+func (w WordPress) getLivenessProbe() *corev1.Probe {
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Port: intstr.FromInt(80),
+			},
+		},
+		InitialDelaySeconds: 30,
+		PeriodSeconds:       20,
+		TimeoutSeconds:      5,
+		FailureThreshold:    6,
+	}
+}
+
+func (w WordPress) IsDeploymentReady() bool {
+	deployment, err := w.Clientset.AppsV1().Deployments(w.Namespace).Get(
+		context.Background(),
+		w.DeploymentName,
+		metav1.GetOptions{},
+	)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Wait for the latest spec to be rolled out and all replicas to pass the readiness probe
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	deploymentReady := deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.UpdatedReplicas == replicas &&
+		deployment.Status.AvailableReplicas == replicas &&
+		deployment.Status.ReadyReplicas == replicas
+
+	// Routes are not usable until the gateway has accepted them
+	if deploymentReady && w.Exposure == EXPOSURE_GATEWAY {
+		return w.IsHTTPRouteReady()
+	}
+
+	return deploymentReady
+}
@@ -0,0 +1,59 @@
+package svc
+
+import (
+	"context"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	K8S_FAILED_ATTACH_VOLUME_REASON = "FailedAttachVolume"
+	K8S_MULTI_ATTACH_ERROR          = "Multi-Attach error"
+)
+
+func (w WordPress) getUploadsAccessMode() corev1.PersistentVolumeAccessMode {
+	if w.UploadsAccessMode == "" {
+		return corev1.ReadWriteOnce
+	}
+
+	return corev1.PersistentVolumeAccessMode(w.UploadsAccessMode)
+}
+
+func (w WordPress) getDeploymentStrategy() appsv1.DeploymentStrategy {
+	// A ReadWriteOnce volume can only be attached to one node at a time, so the
+	// old pod has to be removed before the new one can start
+	if w.getUploadsAccessMode() == corev1.ReadWriteOnce {
+		return appsv1.DeploymentStrategy{
+			Type: appsv1.RecreateDeploymentStrategyType,
+		}
+	}
+
+	return appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+	}
+}
+
+func (w WordPress) GetMultiAttachError() string {
+	events, err := w.Clientset.CoreV1().Events(w.Namespace).List(
+		context.Background(),
+		metav1.ListOptions{
+			FieldSelector: "involvedObject.kind=Pod,reason=" + K8S_FAILED_ATTACH_VOLUME_REASON,
+		},
+	)
+
+	if err != nil {
+		return ""
+	}
+
+	for _, event := range events.Items {
+		if strings.HasPrefix(event.InvolvedObject.Name, w.DeploymentName+"-") &&
+			strings.Contains(event.Message, K8S_MULTI_ATTACH_ERROR) {
+			return event.Message
+		}
+	}
+
+	return ""
+}
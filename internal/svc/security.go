@@ -0,0 +1,67 @@
+package svc
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	WP_USER_ID                     = 33
+	POD_SECURITY_RESTRICTED        = "restricted"
+	K8S_POD_SECURITY_ENFORCE_LABEL = "pod-security.kubernetes.io/enforce"
+	K8S_UNPRIVILEGED_PORT_SYSCTL   = "net.ipv4.ip_unprivileged_port_start"
+)
+
+func GetPodSecurityLevel(clientset *kubernetes.Clientset, namespace string) string {
+	ns, err := clientset.CoreV1().Namespaces().Get(context.Background(), namespace, metav1.GetOptions{})
+	if err != nil {
+		return ""
+	}
+
+	return ns.Labels[K8S_POD_SECURITY_ENFORCE_LABEL]
+}
+
+func (w WordPress) getPodSecurityContext() *corev1.PodSecurityContext {
+	if !w.Hardened {
+		return nil
+	}
+
+	userId := int64(WP_USER_ID)
+	runAsNonRoot := true
+
+	return &corev1.PodSecurityContext{
+		RunAsUser:    &userId,
+		RunAsGroup:   &userId,
+		RunAsNonRoot: &runAsNonRoot,
+		// Make the uploads volume writable by the www-data user
+		FSGroup: &userId,
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+		// Let Apache bind to port 80 without running as root
+		Sysctls: []corev1.Sysctl{
+			{
+				Name:  K8S_UNPRIVILEGED_PORT_SYSCTL,
+				Value: "0",
+			},
+		},
+	}
+}
+
+func (w WordPress) getContainerSecurityContext() *corev1.SecurityContext {
+	if !w.Hardened {
+		return nil
+	}
+
+	allowPrivilegeEscalation := false
+
+	return &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+	}
+}
@@ -0,0 +1,54 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	K8S_DEFAULT_STORAGE_CLASS_ANNOTATION = "storageclass.kubernetes.io/is-default-class"
+	K8S_DEFAULT_STORAGE_CLASS_SUFFIX     = " (default)"
+)
+
+var K8S_ACCESS_MODES = []string{
+	string(corev1.ReadWriteOnce),
+	string(corev1.ReadWriteMany),
+}
+
+func ValidateVolumeSize(value string) error {
+	// Sizes are given in Gi, so they have to parse the same way as when the PVC is created
+	size, err := resource.ParseQuantity(value + "Gi")
+	if err != nil || size.Sign() <= 0 {
+		return fmt.Errorf("volume size must be a positive number")
+	}
+
+	return nil
+}
+
+func GetStorageClasses(clientset *kubernetes.Clientset) []string {
+	storageClasses, err := clientset.StorageV1().StorageClasses().List(
+		context.Background(),
+		metav1.ListOptions{},
+	)
+
+	if err != nil {
+		return []string{}
+	}
+
+	// List the default storage class first
+	names := []string{}
+	for _, storageClass := range storageClasses.Items {
+		if storageClass.Annotations[K8S_DEFAULT_STORAGE_CLASS_ANNOTATION] == "true" {
+			names = append([]string{storageClass.Name + K8S_DEFAULT_STORAGE_CLASS_SUFFIX}, names...)
+		} else {
+			names = append(names, storageClass.Name)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,177 @@
+package svc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	TLS_SOURCE_CERT_MANAGER    = "cert-manager issuer"
+	TLS_SOURCE_EXISTING_SECRET = "Existing TLS secret"
+	TLS_SOURCE_UPLOAD          = "Upload certificate and key"
+	K8S_CLUSTER_ISSUER_KIND    = "ClusterIssuer"
+	K8S_ISSUER_KIND            = "Issuer"
+	K8S_UPLOADED_TLS_SUFFIX    = "-tls"
+)
+
+var K8S_CLUSTER_ISSUER_RESOURCE = schema.GroupVersionResource{
+	Group:    "cert-manager.io",
+	Version:  "v1",
+	Resource: "clusterissuers",
+}
+
+var K8S_ISSUER_RESOURCE = schema.GroupVersionResource{
+	Group:    "cert-manager.io",
+	Version:  "v1",
+	Resource: "issuers",
+}
+
+var K8S_CERTIFICATE_RESOURCE = schema.GroupVersionResource{
+	Group:    "cert-manager.io",
+	Version:  "v1",
+	Resource: "certificates",
+}
+
+var TLS_SOURCES = []string{
+	TLS_SOURCE_CERT_MANAGER,
+	TLS_SOURCE_EXISTING_SECRET,
+	TLS_SOURCE_UPLOAD,
+}
+
+func GetIssuers(restConfig *rest.Config, namespace string) []string {
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return []string{}
+	}
+
+	// Issuers are formatted as <kind>/<name>
+	issuers := []string{}
+
+	clusterIssuers, err := client.Resource(K8S_CLUSTER_ISSUER_RESOURCE).List(context.Background(), metav1.ListOptions{})
+	if err == nil {
+		for _, issuer := range clusterIssuers.Items {
+			issuers = append(issuers, K8S_CLUSTER_ISSUER_KIND+"/"+issuer.GetName())
+		}
+	}
+
+	namespacedIssuers, err := client.Resource(K8S_ISSUER_RESOURCE).Namespace(namespace).List(context.Background(), metav1.ListOptions{})
+	if err == nil {
+		for _, issuer := range namespacedIssuers.Items {
+			issuers = append(issuers, K8S_ISSUER_KIND+"/"+issuer.GetName())
+		}
+	}
+
+	return issuers
+}
+
+func ValidateKeyPair(certFile, keyFile string) error {
+	_, err := tls.LoadX509KeyPair(certFile, keyFile)
+	return err
+}
+
+func (w WordPress) getTlsSecretName() string {
+	switch w.TlsSource {
+	case TLS_SOURCE_EXISTING_SECRET:
+		return w.TlsSecretName
+	case TLS_SOURCE_UPLOAD:
+		return w.DeploymentName + K8S_UPLOADED_TLS_SUFFIX
+	}
+
+	return w.Namespace + "-tls"
+}
+
+func (w WordPress) getIssuerAnnotations() map[string]string {
+	if w.TlsSource != TLS_SOURCE_CERT_MANAGER {
+		return map[string]string{}
+	}
+
+	if w.IssuerKind == K8S_ISSUER_KIND {
+		return map[string]string{"cert-manager.io/issuer": w.IssuerName}
+	}
+
+	return map[string]string{"cert-manager.io/cluster-issuer": w.IssuerName}
+}
+
+func (w WordPress) CreateTlsSecret(successMessage, existsMessage string) *corev1.Secret {
+	if !w.IngressTls || w.Exposure != EXPOSURE_INGRESS || w.TlsSource != TLS_SOURCE_UPLOAD {
+		return nil
+	}
+
+	cert, err := os.ReadFile(w.TlsCertFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	key, err := os.ReadFile(w.TlsKeyFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Catch mismatched or malformed files before the ingress controller does
+	if _, err := tls.X509KeyPair(cert, key); err != nil {
+		log.Fatalf("invalid TLS certificate or key: %s", err)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      w.getTlsSecretName(),
+			Namespace: w.Namespace,
+			Labels:    getManagedByLabels(),
+		},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       cert,
+			corev1.TLSPrivateKeyKey: key,
+		},
+		Type: corev1.SecretTypeTLS,
+	}
+
+	createdSecret, err := w.Clientset.CoreV1().Secrets(secret.Namespace).Create(
+		context.Background(),
+		secret,
+		metav1.CreateOptions{},
+	)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			fmt.Print(existsMessage)
+		} else {
+			log.Fatal(err)
+		}
+	} else {
+		fmt.Printf(successMessage, createdSecret.Name)
+	}
+
+	return createdSecret
+}
+
+func (w WordPress) IsCertificateManaged() bool {
+	return w.IngressTls && w.Exposure == EXPOSURE_INGRESS && w.TlsSource == TLS_SOURCE_CERT_MANAGER
+}
+
+func (w WordPress) IsCertificateReady() bool {
+	// cert-manager names the certificate it creates for an ingress after the TLS secret
+	certificate, err := w.getDynamicClient().Resource(K8S_CERTIFICATE_RESOURCE).Namespace(w.Namespace).Get(
+		context.Background(),
+		w.getTlsSecretName(),
+		metav1.GetOptions{},
+	)
+
+	if err != nil {
+		return false
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+
+	return hasTrueCondition(conditions, "Ready")
+}
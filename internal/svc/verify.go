@@ -0,0 +1,172 @@
+package svc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	VERIFY_HTTP_TIMEOUT = 15 * time.Second
+)
+
+func (w WordPress) VerifyDns(successMessage, mismatchMessage, failedMessage string) bool {
+	resolved, err := net.LookupHost(w.Hostname)
+	if err != nil {
+		fmt.Printf(failedMessage, w.Hostname, err)
+		return false
+	}
+
+	expected := w.getLoadBalancerAddresses()
+
+	// Without a known load balancer address there is nothing to compare against
+	if len(expected) == 0 {
+		fmt.Printf(successMessage, w.Hostname, strings.Join(resolved, ", "))
+		return true
+	}
+
+	for _, address := range resolved {
+		for _, expectedAddress := range expected {
+			if address == expectedAddress {
+				fmt.Printf(successMessage, w.Hostname, strings.Join(resolved, ", "))
+				return true
+			}
+		}
+	}
+
+	fmt.Printf(mismatchMessage, w.Hostname, strings.Join(resolved, ", "), strings.Join(expected, ", "))
+
+	return false
+}
+
+func (w WordPress) VerifyHttp(successMessage, statusMessage, tlsMessage, failedMessage string) bool {
+	url := w.GetDeploymentUrl()
+
+	client := &http.Client{
+		Timeout: VERIFY_HTTP_TIMEOUT,
+		// Redirects count as a working site, so there is no need to follow them
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		fmt.Printf(failedMessage, url, err)
+		return false
+	}
+
+	if w.BasicAuth {
+		request.SetBasicAuth(w.BasicAuthUser, w.BasicAuthPass)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		if isTlsError(err) {
+			fmt.Printf(tlsMessage, url, err)
+		} else {
+			fmt.Printf(failedMessage, url, err)
+		}
+		return false
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 400 {
+		fmt.Printf(statusMessage, url, response.Status)
+		return false
+	}
+
+	fmt.Printf(successMessage, url, response.Status)
+
+	return true
+}
+
+func (w WordPress) getLoadBalancerAddresses() []string {
+	hosts := []string{}
+
+	switch w.Exposure {
+	case EXPOSURE_INGRESS:
+		ingress, err := w.Clientset.NetworkingV1().Ingresses(w.Namespace).Get(
+			context.Background(),
+			w.DeploymentName,
+			metav1.GetOptions{},
+		)
+
+		if err != nil {
+			return hosts
+		}
+
+		for _, lbIngress := range ingress.Status.LoadBalancer.Ingress {
+			hosts = append(hosts, lbIngress.IP, lbIngress.Hostname)
+		}
+	case EXPOSURE_GATEWAY:
+		hosts = w.getGatewayAddresses()
+	}
+
+	return resolveAddresses(hosts)
+}
+
+func (w WordPress) getGatewayAddresses() []string {
+	hosts := []string{}
+
+	gateway, err := w.getDynamicClient().Resource(K8S_GATEWAY_RESOURCE).Namespace(w.GatewayNamespace).Get(
+		context.Background(),
+		w.GatewayName,
+		metav1.GetOptions{},
+	)
+
+	if err != nil {
+		return hosts
+	}
+
+	addresses, _, _ := unstructured.NestedSlice(gateway.Object, "status", "addresses")
+	for _, item := range addresses {
+		if address, ok := item.(map[string]interface{}); ok {
+			value, _ := address["value"].(string)
+			hosts = append(hosts, value)
+		}
+	}
+
+	return hosts
+}
+
+// Load balancers exposed by hostname are resolved to their IPs
+func resolveAddresses(hosts []string) []string {
+	addresses := []string{}
+	for _, host := range hosts {
+		if host == "" {
+			continue
+		}
+
+		if net.ParseIP(host) != nil {
+			addresses = append(addresses, host)
+		} else if resolved, err := net.LookupHost(host); err == nil {
+			addresses = append(addresses, resolved...)
+		}
+	}
+
+	return addresses
+}
+
+func isTlsError(err error) bool {
+	var certificateError *tls.CertificateVerificationError
+	var hostnameError x509.HostnameError
+	var authorityError x509.UnknownAuthorityError
+	var invalidError x509.CertificateInvalidError
+	var recordError tls.RecordHeaderError
+
+	return errors.As(err, &certificateError) ||
+		errors.As(err, &hostnameError) ||
+		errors.As(err, &authorityError) ||
+		errors.As(err, &invalidError) ||
+		errors.As(err, &recordError)
+}
@@ -15,6 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 const (
@@ -22,6 +23,7 @@ const (
 	WP_PLACEHOLDER_DEPLOYMENT = "wordpress"
 	WP_PLACEHOLDER_WP_UPLOADS = "2"
 	WP_PLACEHOLDER_HOSTNAME   = "myproject.example.com"
+	WP_PLACEHOLDER_REGISTRIES = "ghcr.io, quay.io"
 	WP_PLACEHOLDER_DB_HOST    = "db.namespace.svc.cluster.local"
 	WP_PLACEHOLDER_DB_NAME    = "my_project"
 	WP_PLACEHOLDER_DB_USER    = "root"
@@ -29,9 +31,27 @@ const (
 	K8S_DB_SECRET_KEY         = "WORDPRESS_DB_PASSWORD"
 	K8S_DB_SECRET_NAME        = "wp-db-password"
 	K8S_REGISTRY_SECRET_NAME  = "wp-registry-auth"
+	K8S_SALTS_SECRET_NAME     = "wp-salts"
+	WP_SALT_LENGTH            = 64
 	K8S_CLUSTER_ISSUER_NAME   = "letsencrypt"
+	K8S_MANAGED_BY_LABEL      = "app.kubernetes.io/managed-by"
+	K8S_MANAGED_BY_VALUE      = "kade"
+	EXPOSURE_INGRESS          = "Public (Ingress)"
+	EXPOSURE_GATEWAY          = "Public (Gateway API)"
+	EXPOSURE_INTERNAL         = "Internal only (port-forward)"
 )
 
+var WP_SALT_KEYS = []string{
+	"WORDPRESS_AUTH_KEY",
+	"WORDPRESS_SECURE_AUTH_KEY",
+	"WORDPRESS_LOGGED_IN_KEY",
+	"WORDPRESS_NONCE_KEY",
+	"WORDPRESS_AUTH_SALT",
+	"WORDPRESS_SECURE_AUTH_SALT",
+	"WORDPRESS_LOGGED_IN_SALT",
+	"WORDPRESS_NONCE_SALT",
+}
+
 type DockerConfig struct {
 	Auths map[string]struct {
 		Username string
@@ -41,23 +61,69 @@ type DockerConfig struct {
 }
 
 type WordPress struct {
-	Namespace             string
-	DeploymentName        string
-	UploadsVolSize        string
-	ContainerImage        string
-	ContainerRegistryUri  string
-	ContainerRegistryUser string
-	ContainerRegistryPass string
-	Hostname              string
-	IngressTls            bool
-	DatabaseHost          string
-	DatabaseName          string
-	DatabaseUser          string
-	DatabasePass          string
-	Clientset             *kubernetes.Clientset
+	Namespace                  string
+	RancherProject             *RancherProject
+	QuotaProfile               *QuotaProfile
+	DeploymentName             string
+	UploadsVolSize             string
+	UploadsAccessMode          string
+	StorageClass               string
+	ContainerImage             string
+	RegistryCredentials        []RegistryCredentials
+	ImagePullSecret            string
+	Exposure                   string
+	IngressClass               string
+	IngressController          string
+	GatewayName                string
+	GatewayNamespace           string
+	Hostname                   string
+	Aliases                    []string
+	RedirectAliases            bool
+	IngressTls                 bool
+	TlsSource                  string
+	IssuerKind                 string
+	IssuerName                 string
+	TlsSecretName              string
+	TlsCertFile                string
+	TlsKeyFile                 string
+	NetworkPolicies            bool
+	Hardened                   bool
+	IngressControllerNamespace string
+	BasicAuth                  bool
+	BasicAuthUser              string
+	BasicAuthPass              string
+	AllowedSourceRanges        []string
+	DatabaseHost               string
+	DatabaseName               string
+	DatabaseUser               string
+	DatabasePass               string
+	Debug                      bool
+	ExtraEnv                   map[string]string
+	Constants                  map[string]string
+	ConfigExtra                string
+	PhpIni                     map[string]string
+	CpuRequest                 string
+	MemoryRequest              string
+	CpuLimit                   string
+	MemoryLimit                string
+	Bootstrap                  bool
+	SiteTitle                  string
+	AdminUser                  string
+	AdminEmail                 string
+	AdminPass                  string
+	Locale                     string
+	PermalinkStructure         string
+	Plugins                    []string
+	Themes                     []string
+	Clientset                  *kubernetes.Clientset
+	RestConfig                 *rest.Config
 }
 
 func (w WordPress) GetDeploymentUrl() string {
+	if w.Exposure == EXPOSURE_INTERNAL {
+		return fmt.Sprintf("http://localhost:%d", K8S_PORT_FORWARD_LOCAL_PORT)
+	}
+
 	wordpressUrl := w.Hostname
 
 	if w.IngressTls {
@@ -72,10 +138,19 @@ func (w WordPress) GetDeploymentUrl() string {
 func (w WordPress) CreateNamespace(successMessage, existsMessage string) *corev1.Namespace {
 	namespace := &corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: w.Namespace,
+			Name:   w.Namespace,
+			Labels: getManagedByLabels(),
 		},
 	}
 
+	// Assign the namespace to a Rancher project, label values can't contain the cluster prefix
+	if w.RancherProject != nil {
+		namespace.ObjectMeta.Annotations = map[string]string{
+			K8S_RANCHER_PROJECT_ID_FIELD: w.RancherProject.GetProjectId(),
+		}
+		namespace.ObjectMeta.Labels[K8S_RANCHER_PROJECT_ID_FIELD] = w.RancherProject.Name
+	}
+
 	createdNamespace, err := w.Clientset.CoreV1().Namespaces().Create(
 		context.Background(),
 		namespace,
@@ -103,7 +178,7 @@ func (w WordPress) CreatePvc(successMessage, existsMessage string) *corev1.Persi
 		},
 		Spec: corev1.PersistentVolumeClaimSpec{
 			AccessModes: []corev1.PersistentVolumeAccessMode{
-				corev1.ReadWriteOnce,
+				w.getUploadsAccessMode(),
 			},
 			Resources: corev1.ResourceRequirements{
 				Requests: corev1.ResourceList{
@@ -113,6 +188,11 @@ func (w WordPress) CreatePvc(successMessage, existsMessage string) *corev1.Persi
 		},
 	}
 
+	// Leave the storage class empty to use the cluster default
+	if w.StorageClass != "" {
+		pvc.Spec.StorageClassName = &w.StorageClass
+	}
+
 	createdPvc, err := w.Clientset.
 		CoreV1().
 		PersistentVolumeClaims(w.Namespace).
@@ -162,8 +242,48 @@ func (w WordPress) CreateDbPasswordSecret(successMessage, existsMessage string)
 	return createdSecret
 }
 
-func (w WordPress) CreateRegistryAuthSecret(successMessage, existsMessage, noContainerRegistryCredsMessage string) *corev1.Secret {
-	if w.ContainerRegistryUri == "" || w.ContainerRegistryUser == "" || w.ContainerRegistryPass == "" {
+func (w WordPress) CreateSaltsSecret(successMessage, existsMessage string) *corev1.Secret {
+	salts := map[string][]byte{}
+	for _, key := range WP_SALT_KEYS {
+		salts[key] = []byte(utils.GeneratePassword(WP_SALT_LENGTH))
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      K8S_SALTS_SECRET_NAME,
+			Namespace: w.Namespace,
+			Labels:    getManagedByLabels(),
+		},
+		Data: salts,
+		Type: corev1.SecretTypeOpaque,
+	}
+
+	createdSecret, err := w.Clientset.CoreV1().Secrets(secret.Namespace).Create(
+		context.Background(),
+		secret,
+		metav1.CreateOptions{},
+	)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			fmt.Print(existsMessage)
+		} else {
+			log.Fatal(err)
+		}
+	} else {
+		fmt.Printf(successMessage, createdSecret.Name)
+	}
+
+	return createdSecret
+}
+
+func (w WordPress) CreateRegistryAuthSecret(successMessage, existsMessage, existingSecretMessage, noContainerRegistryCredsMessage string) *corev1.Secret {
+	if w.ImagePullSecret != "" {
+		fmt.Printf(existingSecretMessage, w.ImagePullSecret)
+		return nil
+	}
+
+	if !w.hasRegistryCredentials() {
 		fmt.Print(noContainerRegistryCredsMessage)
 		return nil
 	}
@@ -207,20 +327,17 @@ func (w WordPress) CreateDeployment(successMessage, existsMessage string) *appsv
 	uniqueID := utils.GenerateUniqueID()
 	appLabel := "deployment-" + w.Namespace + "-" + w.DeploymentName + "-" + uniqueID
 
-	wordpressUrl := w.Hostname
-	if w.IngressTls {
-		wordpressUrl = "https://" + wordpressUrl
-	} else {
-		wordpressUrl = "http://" + wordpressUrl
-	}
+	wordpressUrl := w.GetDeploymentUrl()
 
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      w.DeploymentName,
 			Namespace: w.Namespace,
+			Labels:    getManagedByLabels(),
 		},
 		Spec: appsv1.DeploymentSpec{
 			Replicas: utils.Int32Ptr(1),
+			Strategy: w.getDeploymentStrategy(),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{
 					"app": appLabel,
@@ -296,12 +413,68 @@ func (w WordPress) CreateDeployment(successMessage, existsMessage string) *appsv
 		},
 	}
 
-	// Append pull secret if registry credentials are provided
-	if w.ContainerRegistryUri != "" && w.ContainerRegistryUser != "" && w.ContainerRegistryPass != "" {
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	container.Resources = w.getResources()
+	container.ReadinessProbe = w.getReadinessProbe()
+	container.LivenessProbe = w.getLivenessProbe()
+	container.SecurityContext = w.getContainerSecurityContext()
+	deployment.Spec.Template.Spec.SecurityContext = w.getPodSecurityContext()
+
+	// Inject keys and salts so that sessions survive pod restarts
+	for _, key := range WP_SALT_KEYS {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name: key,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					Key: key,
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: K8S_SALTS_SECRET_NAME,
+					},
+				},
+			},
+		})
+	}
+
+	if configExtra := w.getConfigExtra(); configExtra != "" {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  K8S_WORDPRESS_CONFIG_EXTRA,
+			Value: configExtra,
+		})
+	}
+
+	for _, key := range utils.SortedKeys(w.ExtraEnv) {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  key,
+			Value: w.ExtraEnv[key],
+		})
+	}
+
+	// Mount custom PHP settings into the PHP conf.d directory
+	if len(w.PhpIni) > 0 {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      K8S_PHP_CONFIGMAP_NAME,
+			MountPath: K8S_PHP_CONFIG_DIR + "/" + K8S_PHP_CONFIGMAP_KEY,
+			SubPath:   K8S_PHP_CONFIGMAP_KEY,
+		})
+
+		deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: K8S_PHP_CONFIGMAP_NAME,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: K8S_PHP_CONFIGMAP_NAME,
+					},
+				},
+			},
+		})
+	}
+
+	// Append pull secret if registry credentials or an existing pull secret are provided
+	if pullSecret := w.getImagePullSecretName(); pullSecret != "" {
 		deployment.Spec.Template.Spec.ImagePullSecrets = append(
 			deployment.Spec.Template.Spec.ImagePullSecrets,
 			corev1.LocalObjectReference{
-				Name: K8S_REGISTRY_SECRET_NAME,
+				Name: pullSecret,
 			},
 		)
 	}
@@ -340,6 +513,7 @@ func (w WordPress) CreateService(successMessage, existsMessage string) *corev1.S
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      w.DeploymentName,
 			Namespace: w.Namespace,
+			Labels:    getManagedByLabels(),
 		},
 		Spec: corev1.ServiceSpec{
 			Selector: map[string]string{
@@ -375,55 +549,52 @@ func (w WordPress) CreateService(successMessage, existsMessage string) *corev1.S
 	return createdService
 }
 
-func (w WordPress) CreateIngress(successMessage, existsMessage string) *networkingv1.Ingress {
-	pathType := networkingv1.PathTypeImplementationSpecific
+func (w WordPress) CreateIngress(successMessage, existsMessage, skipMessage string) *networkingv1.Ingress {
+	if w.Exposure != EXPOSURE_INGRESS {
+		fmt.Print(skipMessage)
+		return nil
+	}
 
 	ingress := &networkingv1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      w.DeploymentName,
-			Namespace: w.Namespace,
-			Annotations: map[string]string{
-				"nginx.ingress.kubernetes.io/proxy-body-size":       "1G",
-				"nginx.ingress.kubernetes.io/proxy-connect-timeout": "30",
-				"nginx.ingress.kubernetes.io/proxy-read-timeout":    "600",
-				"nginx.ingress.kubernetes.io/proxy-send-timeout":    "600",
-				"nginx.ingress.kubernetes.io/configuration-snippet": "more_set_headers \"X-Robots-Tag: noindex\";\n",
-			},
+			Name:        w.DeploymentName,
+			Namespace:   w.Namespace,
+			Labels:      getManagedByLabels(),
+			Annotations: w.getIngressAnnotations(),
 		},
 		Spec: networkingv1.IngressSpec{
-			Rules: []networkingv1.IngressRule{
-				{
-					Host: w.Hostname,
-					IngressRuleValue: networkingv1.IngressRuleValue{
-						HTTP: &networkingv1.HTTPIngressRuleValue{
-							Paths: []networkingv1.HTTPIngressPath{
-								{
-									PathType: &pathType,
-									Backend: networkingv1.IngressBackend{
-										Service: &networkingv1.IngressServiceBackend{
-											Name: w.DeploymentName,
-											Port: networkingv1.ServiceBackendPort{
-												Number: 80,
-											},
-										},
-									},
-								},
-							},
-						},
-					},
-				},
-			},
+			Rules: []networkingv1.IngressRule{},
 		},
 	}
 
+	// Aliases are served by the redirect ingress when redirecting to the primary hostname
+	hosts := w.GetHostnames()
+	if w.RedirectAliases {
+		hosts = []string{w.Hostname}
+	}
+
+	for _, host := range hosts {
+		ingress.Spec.Rules = append(ingress.Spec.Rules, w.getIngressRule(host))
+	}
+
+	if w.IngressClass != "" {
+		ingress.Spec.IngressClassName = &w.IngressClass
+	}
+
+	for key, value := range w.getAccessAnnotations() {
+		ingress.ObjectMeta.Annotations[key] = value
+	}
+
 	if w.IngressTls {
-		// Append annotation for letsencrypt
-		ingress.ObjectMeta.Annotations["cert-manager.io/cluster-issuer"] = K8S_CLUSTER_ISSUER_NAME
+		// Append annotation for the selected cert-manager issuer
+		for key, value := range w.getIssuerAnnotations() {
+			ingress.ObjectMeta.Annotations[key] = value
+		}
 
-		// Append TLS config
+		// Append TLS config, the certificate covers the aliases as well
 		ingress.Spec.TLS = append(ingress.Spec.TLS, networkingv1.IngressTLS{
-			Hosts:      []string{w.Hostname},
-			SecretName: w.Namespace + "-tls",
+			Hosts:      w.GetHostnames(),
+			SecretName: w.getTlsSecretName(),
 		})
 	}
 
@@ -446,35 +617,34 @@ func (w WordPress) CreateIngress(successMessage, existsMessage string) *networki
 	return createdIngress
 }
 
-func (w WordPress) IsDeploymentReady() bool {
-	deployment, err := w.Clientset.AppsV1().Deployments(w.Namespace).Get(
-		context.Background(),
-		w.DeploymentName,
-		metav1.GetOptions{},
-	)
-
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	return deployment.Status.AvailableReplicas > 0
-}
-
 func (w WordPress) getDockerAuthConfig() DockerConfig {
 	dockerConfig := DockerConfig{
 		Auths: map[string]struct {
 			Username string
 			Password string
 			Auth     string
+		}{},
+	}
+
+	// A single pull secret covers every registry the pod pulls images from
+	for _, credentials := range w.RegistryCredentials {
+		dockerConfig.Auths[credentials.Registry] = struct {
+			Username string
+			Password string
+			Auth     string
 		}{
-			w.ContainerRegistryUri: {
-				Username: w.ContainerRegistryUser,
-				Password: w.ContainerRegistryPass,
-				Auth: base64.StdEncoding.EncodeToString(
-					[]byte(w.ContainerRegistryUser + ":" + w.ContainerRegistryPass)),
-			},
-		},
+			Username: credentials.Username,
+			Password: credentials.Password,
+			Auth: base64.StdEncoding.EncodeToString(
+				[]byte(credentials.Username + ":" + credentials.Password)),
+		}
 	}
 
 	return dockerConfig
 }
+
+func getManagedByLabels() map[string]string {
+	return map[string]string{
+		K8S_MANAGED_BY_LABEL: K8S_MANAGED_BY_VALUE,
+	}
+}
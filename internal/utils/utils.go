@@ -3,6 +3,12 @@ package utils
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 )
 
 func Int32Ptr(i int32) *int32 { return &i }
@@ -15,3 +21,96 @@ func GenerateUniqueID() string {
 
 	return hex.EncodeToString(randomBytes)
 }
+
+func GeneratePassword(length int) string {
+	const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	password := make([]byte, length)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+		if err != nil {
+			panic(err)
+		}
+
+		password[i] = chars[n.Int64()]
+	}
+
+	return string(password)
+}
+
+func SplitList(value string) []string {
+	list := []string{}
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+func ValueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
+
+func ParseKeyValueList(value string) map[string]string {
+	list := map[string]string{}
+
+	for _, item := range SplitList(value) {
+		key, val, _ := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if key != "" {
+			list[key] = strings.TrimSpace(val)
+		}
+	}
+
+	return list
+}
+
+func FormatKeyValueList(list map[string]string) string {
+	items := []string{}
+	for _, key := range SortedKeys(list) {
+		items = append(items, key+"="+list[key])
+	}
+
+	return strings.Join(items, ", ")
+}
+
+func SortedKeys(list map[string]string) []string {
+	keys := []string{}
+	for key := range list {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Expands a leading ~ to the home directory, as the shell would for paths typed in prompts
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+func ValidateFileExists(path string) error {
+	info, err := os.Stat(ExpandHome(path))
+	if err != nil || info.IsDir() {
+		return fmt.Errorf("file %s does not exist", path)
+	}
+
+	return nil
+}